@@ -0,0 +1,188 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+const (
+	RequiredAnyError ValidationErrorType = "required_any"
+)
+
+// AnySchema implements the Schema interface for values of any JSON type,
+// including null. It compiles to the empty JSON schema.
+type AnySchema struct {
+	// value is the decoded JSON value and hasValue denotes it was set
+	value    interface{}
+	hasValue bool
+
+	// isNull denotes that the value is null
+	isNull bool
+
+	description *string
+
+	// isOptional denotes if the value in the schema is optional
+	isOptional bool
+
+	// the result of the last validation
+	result *ValidationResult
+}
+
+// Any creates a new schema that accepts any JSON value
+func Any() *AnySchema {
+	return &AnySchema{
+		result: &ValidationResult{},
+	}
+}
+
+// Description sets the description of the value
+func (a *AnySchema) Description(val string) *AnySchema {
+	a.description = &val
+	return a
+}
+
+// Optional marks the value as optional
+func (a *AnySchema) Optional() *AnySchema {
+	a.isOptional = true
+	return a
+}
+
+func (a *AnySchema) IsOptional() bool {
+	return a.isOptional
+}
+
+// IsNullable implements Schema.IsNullable. Null is always a valid value.
+func (a *AnySchema) IsNullable() bool {
+	return true
+}
+
+// State implements Schema.State
+func (a *AnySchema) State() ValueState {
+	return valueState(a.isNull, a.hasValue)
+}
+
+// Set sets the value, which is decoded the way encoding/json decodes into an
+// interface{}
+func (a *AnySchema) Set(v interface{}) *AnySchema {
+	_ = a.setValue(v)
+	return a
+}
+
+func (a *AnySchema) setValue(val interface{}) error {
+	a.value = val
+	a.hasValue = true
+	a.isNull = val == nil
+	return nil
+}
+
+// Value returns the value as decoded into an interface{}. This method returns
+// (nil, false) if the value hasn't been set or is null.
+func (a *AnySchema) Value() (interface{}, bool) {
+	if a.isNull || !a.hasValue {
+		return nil, false
+	}
+	return a.value, true
+}
+
+func (a *AnySchema) getValue() (interface{}, bool) {
+	if a.isNull {
+		return nil, true
+	}
+	return a.value, a.hasValue
+}
+
+// Validate performs the validation
+func (a *AnySchema) Validate() *ValidationResult {
+	return a.validate(&ParseOptions{})
+}
+
+func (a *AnySchema) validate(_ *ParseOptions) *ValidationResult {
+	a.result = &ValidationResult{}
+
+	if !a.hasValue && !a.isOptional {
+		a.result.AddError(&ValidationError{
+			Type:    RequiredAnyError,
+			Message: "value has not been set",
+		})
+	}
+
+	return a.result
+}
+
+// MarshalJSON implements json.Marshaler
+func (a *AnySchema) MarshalJSON() ([]byte, error) {
+	if !a.hasValue && !a.isOptional {
+		return nil, fmt.Errorf("required field has no value")
+	}
+	return json.Marshal(a.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *AnySchema) UnmarshalJSON(data []byte) error {
+	if err := a.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	if result := a.Validate(); result.HasErrors() {
+		return result.Error()
+	}
+
+	return nil
+}
+
+func (a *AnySchema) decode(data []byte, _ *ParseOptions) error {
+	if len(data) == 0 {
+		a.value = nil
+		a.hasValue = false
+		a.isNull = false
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+
+	return a.setValue(v)
+}
+
+// CompileJSONSchema implements Schema.CompileJSONSchema
+func (a *AnySchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error {
+	if a == nil {
+		return fmt.Errorf("found nil schema interface with JSON tag: %s", jsonTag)
+	}
+
+	propertySchema := &jsonschema.JSONSchema{}
+
+	if a.description != nil {
+		propertySchema.Description = *a.description
+	}
+
+	if !a.isOptional {
+		schema.Required = append(schema.Required, jsonTag)
+	}
+
+	schema.Properties[jsonTag] = propertySchema
+	return nil
+}
+
+// Clone implements Schema.Clone by creating a copy of the AnySchema. The
+// value is shared since decoded JSON values are never modified.
+func (a *AnySchema) Clone() Schema {
+	clone := &AnySchema{
+		value:      a.value,
+		hasValue:   a.hasValue,
+		isNull:     a.isNull,
+		isOptional: a.isOptional,
+		result:     &ValidationResult{},
+	}
+
+	if a.description != nil {
+		desc := *a.description
+		clone.description = &desc
+	}
+
+	return clone
+}
@@ -10,10 +10,10 @@ import (
 const (
 	ArraySchemaType                              = "array"
 	MinItemsError            ValidationErrorType = "min_items"
-	MaxItemsError                                = "max_items"
-	RequiredArrayError                           = "required_array"
-	InvalidElementTypeError                      = "invalid_element_type"
-	MissingElementValueError                     = "missing_element_value"
+	MaxItemsError            ValidationErrorType = "max_items"
+	RequiredArrayError       ValidationErrorType = "required_array"
+	InvalidElementTypeError  ValidationErrorType = "invalid_element_type"
+	MissingElementValueError ValidationErrorType = "missing_element_value"
 )
 
 type ArraySchema struct {
@@ -22,9 +22,30 @@ type ArraySchema struct {
 	minItems      *int
 	maxItems      *int
 	value         []interface{}
+	refinements   []refinement[[]interface{}]
 	isOptional    bool
 	description   *string
 	result        *ValidationResult
+
+	// elements hold the element schemas of the decoded value, so errors of
+	// their transforms aren't lost, and are nil for values given to setValue
+	elements []Schema
+
+	// preprocessors rewrite the JSON value before it's decoded
+	preprocessors []preprocessFunc
+
+	// coerce denotes single values are decoded as one element arrays and
+	// coercion records the last value that was
+	coerce   bool
+	coercion *coercion
+
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[[]interface{}]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
 }
 
 func Array(elementSchema Schema) *ArraySchema {
@@ -60,6 +81,60 @@ func (a *ArraySchema) MaxItems(max int, opts ...ValidationOptions) *ArraySchema
 	return a
 }
 
+// Refine adds a custom validation of the whole array. It only runs once the
+// length and every element are valid.
+func (a *ArraySchema) Refine(check func([]interface{}) bool, opts ...ValidationOptions) *ArraySchema {
+	a.refinements = append(a.refinements, newRefinement(check, opts))
+	return a
+}
+
+// Preprocess adds a function that rewrites the decoded JSON value before it's
+// decoded as an array, e.g. to wrap a single value in an array
+func (a *ArraySchema) Preprocess(fn func(interface{}) interface{}) *ArraySchema {
+	a.preprocessors = append(a.preprocessors, fn)
+	return a
+}
+
+// Coerce decodes a single value that isn't an array as a one element array.
+// Every coerced value is reported as a CoercionWarning when validating.
+func (a *ArraySchema) Coerce() *ArraySchema {
+	a.coerce = true
+	return a
+}
+
+// Nullable marks JSON null as a valid value of the array. Unlike Optional,
+// the key must still be present unless the array is optional as well.
+func (a *ArraySchema) Nullable() *ArraySchema {
+	a.isNullable = true
+	return a
+}
+
+// IsNullable implements Schema.IsNullable
+func (a *ArraySchema) IsNullable() bool {
+	return a.isNullable
+}
+
+// State implements Schema.State
+func (a *ArraySchema) State() ValueState {
+	_, ok := a.getValue()
+	return valueState(a.isNull, ok)
+}
+
+// Default marks the array field as optional and sets the value it takes when
+// it's missing
+func (a *ArraySchema) Default(v []interface{}) *ArraySchema {
+	a.defaultValue = newDefault(v)
+	a.isOptional = true
+	return a
+}
+
+// DefaultFunc is Default with a value built by fn for every missing array
+func (a *ArraySchema) DefaultFunc(fn func() []interface{}) *ArraySchema {
+	a.defaultValue = newDefaultFunc(fn)
+	a.isOptional = true
+	return a
+}
+
 func (a *ArraySchema) Description(desc string) *ArraySchema {
 	a.description = &desc
 	return a
@@ -74,8 +149,22 @@ func (a *ArraySchema) Clone() Schema {
 	clone := &ArraySchema{
 		schemaType:    a.schemaType,
 		elementSchema: a.elementSchema.Clone(), // Clone the element schema
+		refinements:   make([]refinement[[]interface{}], len(a.refinements)),
+		preprocessors: make([]preprocessFunc, len(a.preprocessors)),
 		isOptional:    a.isOptional,
 		result:        &ValidationResult{},
+		coerce:        a.coerce,
+		coercion:      a.coercion,
+	}
+
+	copy(clone.refinements, a.refinements)
+	copy(clone.preprocessors, a.preprocessors)
+
+	if a.elements != nil {
+		clone.elements = make([]Schema, len(a.elements))
+		for i, elem := range a.elements {
+			clone.elements[i] = elem.Clone()
+		}
 	}
 
 	// Deep copy pointers
@@ -98,12 +187,27 @@ func (a *ArraySchema) Clone() Schema {
 		copy(clone.value, a.value)
 	}
 
+	clone.defaultValue = a.defaultValue
+	clone.isNullable = a.isNullable
+	clone.isNull = a.isNull
+
 	return clone
 }
 
 func (a *ArraySchema) Validate() *ValidationResult {
+	return a.validate(&ParseOptions{})
+}
+
+func (a *ArraySchema) validate(opts *ParseOptions) *ValidationResult {
 	a.result = &ValidationResult{}
 
+	if a.isNull {
+		if !a.isNullable {
+			a.result.AddError(newNullError())
+		}
+		return a.result
+	}
+
 	if a.value == nil {
 		if !a.isOptional {
 			a.result.AddError(&ValidationError{
@@ -114,6 +218,10 @@ func (a *ArraySchema) Validate() *ValidationResult {
 		return a.result
 	}
 
+	if a.coercion != nil {
+		a.result.AddWarning(a.coercion.warning())
+	}
+
 	// Check minItems
 	if a.minItems != nil && len(a.value) < *a.minItems {
 		a.result.AddError(&ValidationError{
@@ -134,69 +242,119 @@ func (a *ArraySchema) Validate() *ValidationResult {
 		})
 	}
 
+	if opts.StopOnFirst && a.result.HasErrors() {
+		return a.result
+	}
+
 	// Validate each element
 	for i, elem := range a.value {
-		cloned := a.elementSchema.Clone()
-		if err := cloned.setValue(elem); err != nil {
+		cloned, err := a.element(i, elem)
+		if err != nil {
 			a.result.AddError(&ValidationError{
 				Type:    InvalidElementTypeError,
-				Message: fmt.Sprintf("element %d: %v", i, err),
+				Field:   jsonPointer([]interface{}{i}),
+				Path:    []interface{}{i},
+				Message: err.Error(),
 			})
-			continue
-		}
-
-		if res := cloned.Validate(); res.HasErrors() {
+		} else {
+			res := cloned.validate(opts)
 			for _, err := range res.Errors {
-				err.Message = fmt.Sprintf("element %d: %s", i, err.Message)
+				err.prependPath(i)
 				a.result.AddError(err)
 			}
+			a.result.addWarnings(res.Warnings, i)
+		}
+
+		if opts.StopOnFirst && a.result.HasErrors() {
+			break
 		}
 	}
 
+	runRefinements(a.refinements, a.value, a.result, opts)
+
 	return a.result
 }
 
+// element returns the schema holding the element at index i, either the one
+// it was decoded with or a new one holding the value
+func (a *ArraySchema) element(i int, value interface{}) (Schema, error) {
+	if len(a.elements) == len(a.value) {
+		return a.elements[i], nil
+	}
+
+	elem := a.elementSchema.Clone()
+	if err := elem.setValue(value); err != nil {
+		return nil, err
+	}
+
+	return elem, nil
+}
+
 func (a *ArraySchema) UnmarshalJSON(data []byte) error {
-	a.result = &ValidationResult{}
+	if err := a.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
 
-	if string(data) == "null" {
-		if !a.isOptional {
-			return fmt.Errorf("array is required")
-		}
+	return a.Validate().Error()
+}
+
+func (a *ArraySchema) decode(data []byte, opts *ParseOptions) error {
+	data, err := preprocess(data, a.preprocessors)
+	if err != nil {
+		return fmt.Errorf("invalid array format: %w", err)
+	}
+
+	a.elements = nil
+	a.coercion = nil
+
+	a.isNull = string(data) == "null"
+
+	if len(data) == 0 || string(data) == "null" {
 		a.value = nil
+		applyDefault(a, a.defaultValue)
 		return nil
 	}
 
+	if a.coerce || opts.Coerce {
+		if wrapped, ok := coerceArray(data); ok {
+			a.coercion = newCoercion(data, ArraySchemaType)
+			data = wrapped
+		}
+	}
+
 	var rawElements []json.RawMessage
 	if err := json.Unmarshal(data, &rawElements); err != nil {
 		return fmt.Errorf("invalid array format: %w", err)
 	}
 
-	a.value = make([]interface{}, 0, len(rawElements))
+	value := make([]interface{}, 0, len(rawElements))
+	elements := make([]Schema, 0, len(rawElements))
 	for i, elemData := range rawElements {
 		elem := a.elementSchema.Clone()
-		if err := elem.UnmarshalJSON(elemData); err != nil {
-			a.result.AddError(&ValidationError{
-				Type:    InvalidElementTypeError,
-				Message: fmt.Sprintf("element %d: %v", i, err),
-			})
-			continue
+		if err := elem.decode(elemData, opts); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
 		}
 
-		if val, ok := elem.getValue(); ok {
-			a.value = append(a.value, val)
-		} else {
-			a.result.AddError(&ValidationError{
-				Type:    MissingElementValueError,
-				Message: fmt.Sprintf("element %d: missing value", i),
-			})
+		val, ok := elem.getValue()
+		if !ok {
+			return fmt.Errorf("element %d: missing value", i)
 		}
+
+		value = append(value, val)
+		elements = append(elements, elem)
 	}
 
-	return a.Validate().Error()
+	a.value = value
+	a.elements = elements
+
+	return nil
 }
 
 func (a *ArraySchema) MarshalJSON() ([]byte, error) {
+	if a.isNull {
+		return json.Marshal(nil)
+	}
+
 	if a.value == nil {
 		if a.isOptional {
 			return json.Marshal(nil)
@@ -207,19 +365,24 @@ func (a *ArraySchema) MarshalJSON() ([]byte, error) {
 }
 
 func (a *ArraySchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error {
-	itemsSchema := &jsonschema.JSONSchema{}
-	if err := a.elementSchema.CompileJSONSchema(itemsSchema, ""); err != nil {
+	if a == nil {
+		return fmt.Errorf("found nil schema interface with JSON tag: %s", jsonTag)
+	}
+
+	itemsSchema, err := compileStandalone(a.elementSchema)
+	if err != nil {
 		return fmt.Errorf("failed to compile element schema: %w", err)
 	}
 
 	arraySchema := &jsonschema.JSONSchema{
-		Type:  ArraySchemaType,
+		Type:  jsonSchemaTypes(ArraySchemaType, a.isNullable),
 		Items: itemsSchema,
 	}
 
 	if a.description != nil {
 		arraySchema.Description = *a.description
 	}
+	arraySchema.Default = a.defaultValue.jsonSchemaDefault()
 	if a.minItems != nil {
 		arraySchema.MinItems = a.minItems
 	}
@@ -236,7 +399,7 @@ func (a *ArraySchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag s
 
 func (a *ArraySchema) Value() ([]interface{}, bool) {
 	val, ok := a.getValue()
-	if !ok {
+	if !ok || val == nil {
 		return nil, false
 	}
 	arrayVal, ok := val.([]interface{})
@@ -246,48 +409,37 @@ func (a *ArraySchema) Value() ([]interface{}, bool) {
 	return arrayVal, true
 }
 
-// Set provides a type-safe way to set array values
+// Set sets the elements of the array. Every element is checked against the
+// element schema when validating, like the ones of a decoded array.
 func (a *ArraySchema) Set(values ...interface{}) *ArraySchema {
-	// reset validation results
-	a.result = &ValidationResult{}
-
-	// Create new value slice
-	a.value = make([]interface{}, 0, len(values))
-
-	// Validate each value against the element schema
-	fmt.Printf("%v - %T\n", values, values)
-	for i, val := range values {
-		fmt.Printf("%v - %T\n", val, val)
-		elem := a.elementSchema.Clone()
-		if err := elem.setValue(val); err != nil {
-			a.result.AddError(&ValidationError{
-				Type:    InvalidElementTypeError,
-				Message: fmt.Sprintf("element %d: %v", i, err),
-			})
-			continue
-		}
-
-		// If the value is valid for the element schema, add it
-		if elemVal, ok := elem.getValue(); ok {
-			a.value = append(a.value, elemVal)
-		}
-	}
-
-	fmt.Printf("%v - %T\n", a.value, a.value)
-
+	_ = a.setValue(append(make([]interface{}, 0, len(values)), values...))
 	return a
 }
 
 func (a *ArraySchema) setValue(value interface{}) error {
+	if value == nil && a.isNullable {
+		a.value = nil
+		a.elements = nil
+		a.isNull = true
+		return nil
+	}
+	a.isNull = false
+
 	slice, ok := value.([]interface{})
 	if !ok {
 		return fmt.Errorf("expected array type, got %T", value)
 	}
 	a.value = slice
+	a.elements = nil
+	a.coercion = nil
 	return nil
 }
 
 func (a *ArraySchema) getValue() (interface{}, bool) {
+	if a.isNull {
+		return nil, true
+	}
+
 	if a.value == nil {
 		return nil, false
 	}
@@ -8,7 +8,8 @@ import (
 )
 
 // boolValidatorFunc is a validation function that expects a boolean for validation
-type boolValidatorFunc func(bool)
+// and returns a ValidationError if the boolean is invalid
+type boolValidatorFunc func(bool) *ValidationError
 
 const (
 	BoolRequiredError    ValidationErrorType = "required"
@@ -22,8 +23,24 @@ type BoolSchema struct {
 	// validators are the registered functions to validate the string against
 	validators []boolValidatorFunc
 
+	// preprocessors rewrite the JSON value before it's decoded
+	preprocessors []preprocessFunc
+
 	value *bool // Using pointer to handle null values
 
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[bool]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
+
+	// coerce denotes spellings like "yes" or "off" are decoded as booleans and
+	// coercion records the last value that was
+	coerce   bool
+	coercion *coercion
+
 	description *string
 
 	// isOptional denotes if the bool value in the schema is optional
@@ -33,6 +50,39 @@ type BoolSchema struct {
 	result *ValidationResult
 }
 
+// Nullable marks JSON null as a valid value of the bool. Unlike Optional,
+// the key must still be present unless the bool is optional as well.
+func (b *BoolSchema) Nullable() *BoolSchema {
+	b.isNullable = true
+	return b
+}
+
+// IsNullable implements Schema.IsNullable
+func (b *BoolSchema) IsNullable() bool {
+	return b.isNullable
+}
+
+// State implements Schema.State
+func (b *BoolSchema) State() ValueState {
+	_, ok := b.getValue()
+	return valueState(b.isNull, ok)
+}
+
+// Default marks the bool field as optional and sets the value it takes when
+// it's missing
+func (b *BoolSchema) Default(v bool) *BoolSchema {
+	b.defaultValue = newDefault(v)
+	b.isOptional = true
+	return b
+}
+
+// DefaultFunc is Default with a value built by fn for every missing bool
+func (b *BoolSchema) DefaultFunc(fn func() bool) *BoolSchema {
+	b.defaultValue = newDefaultFunc(fn)
+	b.isOptional = true
+	return b
+}
+
 // Optional marks the bool field as optional
 func (b *BoolSchema) Optional() *BoolSchema {
 	b.isOptional = true
@@ -52,16 +102,26 @@ func Bool() *BoolSchema {
 }
 
 func (b *BoolSchema) Set(v bool) *BoolSchema {
+	b.isNull = false
 	b.value = &v
+	b.coercion = nil
 	return b
 }
 
 func (b *BoolSchema) setValue(val interface{}) error {
+	if val == nil && b.isNullable {
+		b.value = nil
+		b.isNull = true
+		return nil
+	}
+	b.isNull = false
+
 	s, ok := val.(bool)
 	if !ok {
 		return fmt.Errorf("expected bool value, got %T", val)
 	}
 	b.value = &s
+	b.coercion = nil
 	return nil
 }
 
@@ -70,20 +130,66 @@ func (b *BoolSchema) Description(val string) *BoolSchema {
 	return b
 }
 
+// Refine adds a custom validation. The check returns false for invalid
+// booleans, which reports a CustomError with the message from the options.
+func (b *BoolSchema) Refine(check func(bool) bool, opts ...ValidationOptions) *BoolSchema {
+	b.validators = append(b.validators, boolValidatorFunc(newRefinement(check, opts)))
+	return b
+}
+
+// Preprocess adds a function that rewrites the decoded JSON value before it's
+// decoded as a bool, e.g. to accept "yes" and "no"
+func (b *BoolSchema) Preprocess(fn func(interface{}) interface{}) *BoolSchema {
+	b.preprocessors = append(b.preprocessors, fn)
+	return b
+}
+
+// Coerce decodes the common spellings of booleans in strings, like "true",
+// "yes" or "off", and the numbers 1 and 0 as booleans. Every coerced value is
+// reported as a CoercionWarning when validating.
+func (b *BoolSchema) Coerce() *BoolSchema {
+	b.coerce = true
+	return b
+}
+
 // Validate performs the validation
 func (b *BoolSchema) Validate() *ValidationResult {
+	return b.validate(&ParseOptions{})
+}
+
+func (b *BoolSchema) validate(opts *ParseOptions) *ValidationResult {
 	b.result = &ValidationResult{}
 
+	if b.isNull {
+		if !b.isNullable {
+			b.result.AddError(newNullError())
+		}
+		return b.result
+	}
+
 	val, ok := b.Value()
-	if !ok && b.isOptional {
-		b.result.AddError(&ValidationError{
-			Type:    BoolRequiredError,
-			Message: "bool has not been set",
-		})
+	if !ok {
+		if !b.isOptional {
+			b.result.AddError(&ValidationError{
+				Type:    BoolRequiredError,
+				Message: "bool has not been set",
+			})
+		}
+		return b.result
+	}
+
+	if b.coercion != nil {
+		b.result.AddWarning(b.coercion.warning())
 	}
 
 	for _, validator := range b.validators {
-		validator(val)
+		if err := validator(val); err != nil {
+			b.result.AddError(err)
+
+			if opts.StopOnFirst {
+				break
+			}
+		}
 	}
 
 	return b.result
@@ -91,6 +197,10 @@ func (b *BoolSchema) Validate() *ValidationResult {
 
 // UnmarshalJSON implements json.Unmarshaler
 func (b *BoolSchema) MarshalJSON() ([]byte, error) {
+	if b.isNull {
+		return json.Marshal(nil)
+	}
+
 	if b.value == nil {
 		if b.isOptional {
 			return json.Marshal(nil)
@@ -103,38 +213,53 @@ func (b *BoolSchema) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements json.Unmarshaler
 func (b *BoolSchema) UnmarshalJSON(data []byte) error {
-	// Handle null values
-	if string(data) == "null" {
-		if !b.isOptional {
-			return fmt.Errorf("validation failed: field is required")
-		}
+	if err := b.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	// Run validation and return errors if there are any
+	if result := b.Validate(); result.HasErrors() {
+		return result.Error()
+	}
+
+	return nil
+}
+
+func (b *BoolSchema) decode(data []byte, opts *ParseOptions) error {
+	data, err := preprocess(data, b.preprocessors)
+	if err != nil {
+		return fmt.Errorf("invalid bool value: %w", err)
+	}
+
+	b.coercion = nil
+
+	b.isNull = string(data) == "null"
+
+	// Handle null values and missing fields (empty string in JSON). Whether
+	// the value is required is left to the validators
+	if len(data) == 0 || string(data) == "null" {
 		b.value = nil
+		applyDefault(b, b.defaultValue)
 		return nil
 	}
 
-	// Handle missing fields (empty string in JSON)
-	if len(data) == 0 {
-		if !b.isOptional {
-			return fmt.Errorf("validation failed: field is required")
+	if b.coerce || opts.Coerce {
+		if v, ok := coerceBool(data); ok {
+			b.coercion = newCoercion(data, "boolean")
+			b.value = &v
+			return nil
 		}
-		b.value = nil
-		return nil
 	}
 
-	// Unmarshal the string value
+	// Unmarshal the bool value
 	var v bool
 	if err := json.Unmarshal(data, &v); err != nil {
-		return fmt.Errorf("invalid int value: %w", err)
+		return fmt.Errorf("invalid bool value: %w", err)
 	}
 
 	// Store the value
 	b.value = &v
 
-	// Run validation and return errors if there are any
-	if result := b.Validate(); result.HasErrors() {
-		return result.Error()
-	}
-
 	return nil
 }
 
@@ -144,10 +269,16 @@ func (b *BoolSchema) Clone() Schema {
 	clone := &BoolSchema{
 		isOptional: b.isOptional,
 		validators: make([]boolValidatorFunc, len(b.validators)),
+
+		preprocessors: make([]preprocessFunc, len(b.preprocessors)),
+
+		coerce:   b.coerce,
+		coercion: b.coercion,
 	}
 
 	// Deep copy the validators slice
 	copy(clone.validators, b.validators)
+	copy(clone.preprocessors, b.preprocessors)
 
 	if b.description != nil {
 		desc := *b.description
@@ -162,13 +293,17 @@ func (b *BoolSchema) Clone() Schema {
 	// Initialize a new validation result
 	clone.result = &ValidationResult{}
 
+	clone.defaultValue = b.defaultValue
+	clone.isNullable = b.isNullable
+	clone.isNull = b.isNull
+
 	return clone
 }
 
 // Value returns the validated string value
 func (b *BoolSchema) Value() (bool, bool) {
 	val, ok := b.getValue()
-	if !ok {
+	if !ok || val == nil {
 		return false, false
 	}
 	boolVal, ok := val.(bool)
@@ -180,6 +315,10 @@ func (b *BoolSchema) Value() (bool, bool) {
 }
 
 func (b *BoolSchema) getValue() (interface{}, bool) {
+	if b.isNull {
+		return nil, true
+	}
+
 	if b.value == nil {
 		return nil, false
 	}
@@ -192,7 +331,7 @@ func (b *BoolSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag st
 	}
 
 	propertySchema := &jsonschema.JSONSchema{
-		Type: "boolean",
+		Type: jsonSchemaTypes("boolean", b.isNullable),
 	}
 
 	// Add description if present
@@ -200,6 +339,8 @@ func (b *BoolSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag st
 		propertySchema.Description = *b.description
 	}
 
+	propertySchema.Default = b.defaultValue.jsonSchemaDefault()
+
 	// Add to required fields if not optional
 	if !b.IsOptional() {
 		schema.Required = append(schema.Required, jsonTag)
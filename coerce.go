@@ -0,0 +1,96 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	CoercionWarning ValidationErrorType = "coercion"
+)
+
+// coercion records a JSON value of the wrong type that was converted into
+// the type of its schema while decoding
+type coercion struct {
+	// from is the JSON value as decoded into an interface{}
+	from interface{}
+
+	// to is the JSON type the value was converted into
+	to string
+}
+
+// newCoercion records the coercion of the JSON data into the type to
+func newCoercion(data []byte, to string) *coercion {
+	var from interface{}
+	_ = json.Unmarshal(data, &from)
+
+	return &coercion{from: from, to: to}
+}
+
+// warning builds the warning reported for the coercion. A new warning is
+// built every time since their paths are updated by the parent schemas.
+func (c *coercion) warning() *ValidationError {
+	return &ValidationError{
+		Type:     CoercionWarning,
+		Message:  fmt.Sprintf("coerced %s %s to %s", jsonTypeOf(c.from), formatEnumValue(c.from), c.to),
+		Expected: c.to,
+		Actual:   c.from,
+	}
+}
+
+// coerceNumber returns the number in a JSON string, e.g. 42 for "42", and
+// false if the data isn't a string holding a JSON number
+func coerceNumber(data []byte) ([]byte, bool) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, false
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" || !(s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) || !json.Valid([]byte(s)) {
+		return nil, false
+	}
+
+	return []byte(s), true
+}
+
+// coerceBool returns the bool for the common spellings of booleans in JSON
+// strings, like "true", "yes" or "off", and for the numbers 1 and 0
+func coerceBool(data []byte) (bool, bool) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return false, false
+	}
+
+	switch v := v.(type) {
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "t", "yes", "y", "on", "1":
+			return true, true
+		case "false", "f", "no", "n", "off", "0":
+			return false, true
+		}
+
+	case float64:
+		switch v {
+		case 1:
+			return true, true
+		case 0:
+			return false, true
+		}
+	}
+
+	return false, false
+}
+
+// coerceArray wraps a single JSON value into a one element array and returns
+// false if the data is already an array
+func coerceArray(data []byte) ([]byte, bool) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+
+	return []byte("[" + trimmed + "]"), true
+}
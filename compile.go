@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/agent-api/gsv/pkg/jsonschema"
 )
@@ -13,12 +14,82 @@ type CompileSchemaOpts struct {
 	SchemaDescription string
 }
 
-// CompileSchema converts a gsv schema struct into a JSON Schema
-func CompileSchema(schema interface{}, cso *CompileSchemaOpts) ([]byte, error) {
+// CompileTarget denotes the dialect of JSON schema CompileSchema produces
+type CompileTarget int
+
+const (
+	// TargetJSONSchema produces a plain JSON schema
+	TargetJSONSchema CompileTarget = iota
+
+	// TargetOpenAIStrict produces a schema OpenAI Structured Outputs accepts
+	// in strict mode. See compileOpenAIStrict for how the schema is adapted.
+	TargetOpenAIStrict
+)
+
+// String implements fmt.Stringer
+func (t CompileTarget) String() string {
+	switch t {
+	case TargetOpenAIStrict:
+		return "OpenAI strict mode"
+	default:
+		return "JSON Schema"
+	}
+}
+
+// UnsupportedSchemaError is returned by CompileSchema when the schema has
+// constructs that can't be represented in the compile target
+type UnsupportedSchemaError struct {
+	Target CompileTarget
+
+	// Constructs describe every construct that can't be represented, prefixed
+	// with its JSON pointer in the compiled schema
+	Constructs []string
+}
+
+func (e *UnsupportedSchemaError) Error() string {
+	return fmt.Sprintf("schema can't be compiled for %s: %s", e.Target, strings.Join(e.Constructs, "; "))
+}
+
+// CompileSchema converts a gsv schema struct into a JSON Schema. A single
+// schema, like an ObjectSchema, can be compiled as well. The first target
+// given sets the dialect of the compiled schema, a plain JSON schema by
+// default.
+func CompileSchema(schema interface{}, cso *CompileSchemaOpts, target ...CompileTarget) ([]byte, error) {
+	jsonSchema, err := compileJSONSchema(schema, cso)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(target) > 0 {
+		return marshalTarget(jsonSchema, target[0])
+	}
+
+	return marshalTarget(jsonSchema, TargetJSONSchema)
+}
+
+// compileJSONSchema compiles a gsv schema struct or a single schema into a
+// JSON schema without adapting it to a target
+func compileJSONSchema(schema interface{}, cso *CompileSchemaOpts) (*jsonschema.JSONSchema, error) {
+	if s, ok := schema.(Schema); ok {
+		compiled, err := compileStandalone(s)
+		if err != nil {
+			return nil, err
+		}
+
+		if cso.SchemaTitle != "" {
+			compiled.Title = cso.SchemaTitle
+		}
+		if cso.SchemaDescription != "" {
+			compiled.Description = cso.SchemaDescription
+		}
+
+		return compiled, nil
+	}
+
 	jsonSchema := &jsonschema.JSONSchema{
 		Title:       cso.SchemaTitle,
 		Description: cso.SchemaDescription,
-		Type:        "object",
+		Type:        jsonschema.Types(ObjectSchemaType),
 		Properties:  make(map[string]*jsonschema.JSONSchema),
 		Required:    make([]string, 0),
 	}
@@ -27,7 +98,24 @@ func CompileSchema(schema interface{}, cso *CompileSchemaOpts) ([]byte, error) {
 		return nil, err
 	}
 
-	return json.MarshalIndent(jsonSchema, "", "  ")
+	return jsonSchema, nil
+}
+
+// marshalTarget adapts the compiled schema to the target and marshals it
+func marshalTarget(schema *jsonschema.JSONSchema, target CompileTarget) ([]byte, error) {
+	switch target {
+	case TargetJSONSchema:
+
+	case TargetOpenAIStrict:
+		if err := compileOpenAIStrict(schema); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown compile target: %d", target)
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
 }
 
 // compileFields handles recursive field compilation
@@ -44,30 +132,24 @@ func compileFields(schema *jsonschema.JSONSchema, value interface{}) error {
 		fieldType := typ.Field(i)
 
 		// Get JSON tag
-		jsonTag := fieldType.Tag.Get("json")
+		jsonTag, _, _ := strings.Cut(fieldType.Tag.Get("json"), ",")
 		if jsonTag == "" || jsonTag == "-" {
 			continue
 		}
 
 		// Handle different types of fields
 		switch {
-		case isStringSchema(field):
-			stringSchema, _ := field.Interface().(*StringSchema)
-			if err := stringSchema.CompileJSONSchema(schema, jsonTag); err != nil {
-				return err
-			}
-
-		case isIntSchema(field):
-			// TODO - need to test
-			intSchema, _ := field.Interface().(*IntSchema)
-			if err := intSchema.CompileJSONSchema(schema, jsonTag); err != nil {
+		case isSchema(field):
+			// Every schema, including nil ones, compiles itself
+			fieldSchema, _ := field.Interface().(Schema)
+			if err := fieldSchema.CompileJSONSchema(schema, jsonTag); err != nil {
 				return err
 			}
 
 		case isStructOrPtrToStruct(field):
 			// Create a new nested object schema
 			nestedSchema := &jsonschema.JSONSchema{
-				Type:       "object",
+				Type:       jsonschema.Types(ObjectSchemaType),
 				Properties: make(map[string]*jsonschema.JSONSchema),
 				Required:   make([]string, 0),
 			}
@@ -87,3 +169,19 @@ func compileFields(schema *jsonschema.JSONSchema, value interface{}) error {
 
 	return nil
 }
+
+// compileStandalone compiles a schema that isn't an object property, like an
+// array element, into its own JSON schema.
+func compileStandalone(s Schema) (*jsonschema.JSONSchema, error) {
+	const key = "standalone"
+
+	wrapper := &jsonschema.JSONSchema{
+		Properties: make(map[string]*jsonschema.JSONSchema),
+	}
+
+	if err := s.CompileJSONSchema(wrapper, key); err != nil {
+		return nil, err
+	}
+
+	return wrapper.Properties[key], nil
+}
@@ -0,0 +1,191 @@
+package gsv
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+// openAIStrictFormats are the string formats strict mode supports
+var openAIStrictFormats = map[string]bool{
+	"date-time": true,
+	"time":      true,
+	"date":      true,
+	"duration":  true,
+	"email":     true,
+	"hostname":  true,
+	"ipv4":      true,
+	"ipv6":      true,
+	"uuid":      true,
+}
+
+// compileOpenAIStrict adapts a compiled schema in place to the subset of JSON
+// schema OpenAI Structured Outputs accepts in strict mode:
+//
+//   - every object gets "additionalProperties": false and lists every property
+//     in "required", with the optional ones made nullable instead
+//   - "oneOf" becomes "anyOf" and "const" becomes a single value "enum"
+//   - keywords strict mode doesn't support, like "minLength" or the "allOf"
+//     patterns of strings, are dropped. They only validate so Parse still
+//     enforces them.
+//
+// Constructs that change the shape of the data, like records, can't be
+// represented and are reported through an UnsupportedSchemaError.
+func compileOpenAIStrict(schema *jsonschema.JSONSchema) error {
+	c := &openAIStrictCompiler{}
+
+	if !schema.Type.Has(ObjectSchemaType) || len(schema.Type) > 1 {
+		c.unsupported(nil, "the root schema must be an object")
+	}
+
+	c.compile(schema, nil)
+
+	if len(c.constructs) > 0 {
+		return &UnsupportedSchemaError{
+			Target:     TargetOpenAIStrict,
+			Constructs: c.constructs,
+		}
+	}
+
+	return nil
+}
+
+// openAIStrictCompiler collects the constructs that can't be represented while
+// walking the schema
+type openAIStrictCompiler struct {
+	constructs []string
+}
+
+// unsupported records a construct of the schema at path
+func (c *openAIStrictCompiler) unsupported(path []interface{}, construct string) {
+	pointer := jsonPointer(path)
+	if pointer == "" {
+		pointer = "/"
+	}
+	c.constructs = append(c.constructs, fmt.Sprintf("%s: %s", pointer, construct))
+}
+
+// compile adapts the schema at path and every schema nested in it
+func (c *openAIStrictCompiler) compile(schema *jsonschema.JSONSchema, path []interface{}) {
+	if schema == nil {
+		return
+	}
+
+	// String, object and array keywords that only validate
+	schema.MinLength = nil
+	schema.MaxLength = nil
+	schema.MinProperties = nil
+	schema.MaxProperties = nil
+	schema.PropertyNames = nil
+	schema.UniqueItems = nil
+	schema.Default = nil
+	if schema.Format != "" && !openAIStrictFormats[schema.Format] {
+		schema.Format = ""
+	}
+
+	// The extra patterns and formats of strings only validate as well
+	for i, entry := range schema.AllOf {
+		if !stringAllOfEntry(entry) {
+			c.unsupported(appendPath(path, "allOf", i), `"allOf" schemas`)
+		}
+	}
+	schema.AllOf = nil
+
+	if schema.HasConst() {
+		schema.Enum = []interface{}{schema.Const}
+		schema.RemoveConst()
+	}
+
+	if len(schema.OneOf) > 0 {
+		schema.AnyOf = append(schema.AnyOf, schema.OneOf...)
+		schema.OneOf = nil
+	}
+
+	if schema.Not != nil {
+		c.unsupported(path, `"not" schemas`)
+	}
+
+	if len(schema.PatternProperties) > 0 {
+		c.unsupported(path, "records with a key pattern")
+	}
+
+	if schema.Type.Has(ObjectSchemaType) || schema.Properties != nil {
+		c.compileObject(schema, path)
+	}
+
+	c.compile(schema.Items, appendPath(path, "items"))
+	for i, variant := range schema.AnyOf {
+		c.compile(variant, appendPath(path, "anyOf", i))
+	}
+}
+
+// compileObject requires every property and disallows additional ones
+func (c *openAIStrictCompiler) compileObject(schema *jsonschema.JSONSchema, path []interface{}) {
+	if additional := schema.AdditionalProperties; additional != nil {
+		if _, ok := additional.IsBool(); !ok {
+			c.unsupported(path, "objects with dynamic keys, like records and catchall objects")
+		}
+	}
+	schema.AdditionalProperties = jsonschema.Bool(false)
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, key := range schema.Required {
+		required[key] = true
+	}
+
+	keys := make([]string, 0, len(schema.Properties))
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		c.compile(schema.Properties[key], appendPath(path, "properties", key))
+
+		if !required[key] {
+			schema.Properties[key] = nullable(schema.Properties[key])
+			schema.Required = append(schema.Required, key)
+		}
+	}
+}
+
+// nullable returns the compiled schema allowing null as well, which is how
+// strict mode represents optional properties
+func nullable(schema *jsonschema.JSONSchema) *jsonschema.JSONSchema {
+	switch {
+	case len(schema.Type) > 0:
+		if !schema.Type.Has(NullSchemaType) {
+			schema.Type = append(schema.Type, NullSchemaType)
+		}
+		if len(schema.Enum) > 0 && !containsNil(schema.Enum) {
+			schema.Enum = append(schema.Enum, nil)
+		}
+		return schema
+
+	case len(schema.AnyOf) > 0:
+		schema.AnyOf = append(schema.AnyOf, &jsonschema.JSONSchema{Type: jsonschema.Types(NullSchemaType)})
+		return schema
+
+	case len(schema.Enum) > 0:
+		if !containsNil(schema.Enum) {
+			schema.Enum = append(schema.Enum, nil)
+		}
+		return schema
+
+	default:
+		return &jsonschema.JSONSchema{
+			AnyOf: []*jsonschema.JSONSchema{schema, {Type: jsonschema.Types(NullSchemaType)}},
+		}
+	}
+}
+
+// containsNil reports whether the enum values include null
+func containsNil(values []interface{}) bool {
+	for _, v := range values {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
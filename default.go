@@ -0,0 +1,52 @@
+package gsv
+
+// defaultValue is the value a schema takes when its value is missing. It's
+// either a fixed value, compiled to the "default" keyword, or built by a
+// function every time it's applied. Schemas apply it with applyDefault.
+type defaultValue[T any] struct {
+	value T
+	fn    func() T
+}
+
+// newDefault returns the default for a fixed value
+func newDefault[T any](v T) *defaultValue[T] {
+	return &defaultValue[T]{value: v}
+}
+
+// newDefaultFunc returns the default built by fn
+func newDefaultFunc[T any](fn func() T) *defaultValue[T] {
+	if fn == nil {
+		panic("default function cannot be nil")
+	}
+
+	return &defaultValue[T]{fn: fn}
+}
+
+// get returns the value of the default
+func (d *defaultValue[T]) get() T {
+	if d.fn != nil {
+		return d.fn()
+	}
+	return d.value
+}
+
+// jsonSchemaDefault returns the value for the "default" keyword of the
+// compiled JSON schema. Defaults built by a function aren't known in advance
+// so nil is returned for them, the same as for schemas without a default.
+func (d *defaultValue[T]) jsonSchemaDefault() interface{} {
+	if d == nil || d.fn != nil {
+		return nil
+	}
+	return d.value
+}
+
+// applyDefault sets the value of schema to the default once decode is done
+// with it. It only applies when the value is absent, so a JSON null is never
+// replaced by the default. Defaults are applied at the end of decode rather than when
+// the value is read, so reading a schema never changes its state.
+func applyDefault[T any](schema Schema, d *defaultValue[T]) {
+	if d == nil || schema.State() != Absent {
+		return
+	}
+	_ = schema.setValue(d.get())
+}
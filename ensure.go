@@ -1,7 +1,6 @@
 package gsv
 
 import (
-	"fmt"
 	"reflect"
 )
 
@@ -18,78 +17,75 @@ import (
 //
 // A ValidationResult is returned which wraps all errors and a boolean error signal
 func ensure[T any](t T) *ValidationResult {
-	return ensureRecursive(reflect.ValueOf(t), "")
+	return ensureRecursive(reflect.ValueOf(t), nil, &ParseOptions{})
 }
 
-func ensureRecursive(v reflect.Value, path string) *ValidationResult {
+// ensureRecursive validates v and, if v is a struct, all of its fields. The
+// walk stops as soon as an error is found when opts.StopOnFirst is set and
+// schemas without a value are skipped when opts.SkipMissing is set.
+//
+// The path holds the JSON keys leading up to v and is used to build the field
+// path of every error.
+func ensureRecursive(v reflect.Value, path []interface{}, opts *ParseOptions) *ValidationResult {
 	// Initialize a new ValidationResult to collect all errors
 	result := &ValidationResult{}
 
-	// Handle pointers by getting their underlying value
-	if v.Kind() == reflect.Ptr {
+	// Handle pointers and interfaces by getting their underlying value
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
 		if v.IsNil() {
 			return result
 		}
 
+		// Schemas are implemented on pointer receivers so they need to be
+		// checked before dereferencing
+		if v.CanInterface() {
+			if schema, ok := v.Interface().(Schema); ok {
+				return ensureSchema(schema, path, opts)
+			}
+		}
+
 		v = v.Elem()
 	}
 
-	fmt.Printf("Processing value of type: %v, kind: %v\n", v.Type(), v.Kind())
+	if v.Kind() != reflect.Struct {
+		return result
+	}
 
-	// If it's a struct, process each field
-	if v.Kind() == reflect.Struct {
-		// First check if the struct itself implements GSVSchema
-		if v.CanInterface() {
-			fmt.Printf("Checking if %v implements GSVSchema\n", v.Type())
-			if schema, ok := v.Interface().(Schema); ok {
-				fmt.Printf("Found GSVSchema implementation: %+v\n", schema)
-				if schemaResult := schema.Validate(); schemaResult.HasErrors() {
-					for _, err := range schemaResult.Errors {
-						if path != "" {
-							err.Field = fmt.Sprintf("%s.%s", path, err.Field)
-						}
+	// Process each field
+	for _, field := range jsonFields(v) {
+		fieldPath := appendPath(path, field.name)
 
-						result.AddError(err)
-					}
-				}
+		fieldResult := ensureRecursive(field.value, fieldPath, opts)
+		result.Warnings = append(result.Warnings, fieldResult.Warnings...)
 
+		if fieldResult.HasErrors() {
+			result.Errors = append(result.Errors, fieldResult.Errors...)
+
+			if opts.StopOnFirst {
+				return result
 			}
 		}
+	}
 
-		// Then process each field
-		for i := 0; i < v.NumField(); i++ {
-			field := v.Field(i)
-			fieldType := v.Type().Field(i)
-
-			// Build the field path
-			fieldPath := fieldType.Name
-			if path != "" {
-				fieldPath = fmt.Sprintf("%s.%s", path, fieldType.Name)
-			}
+	return result
+}
 
-			fmt.Printf("Processing field: %s of type %v (kind: %v)\n",
-				fieldType.Name, field.Type(), field.Kind())
-
-			// Try to validate the field directly first
-			if field.CanInterface() {
-				fmt.Printf("Checking if field %s implements GSVSchema\n", fieldType.Name)
-				if schema, ok := field.Interface().(Schema); ok {
-					fmt.Printf("Found GSVSchema implementation on field %s\n", fieldType.Name)
-					if fieldResult := schema.Validate(); fieldResult.HasErrors() {
-						for _, err := range fieldResult.Errors {
-							err.Field = fieldPath
-							result.AddError(err)
-						}
-					}
-				}
-			}
+// ensureSchema runs the validators of a single schema found at path.
+func ensureSchema(schema Schema, path []interface{}, opts *ParseOptions) *ValidationResult {
+	result := &ValidationResult{}
 
-			// Then recurse on the field
-			if fieldResult := ensureRecursive(field, fieldPath); fieldResult.HasErrors() {
-				result.Errors = append(result.Errors, fieldResult.Errors...)
-			}
+	if opts.SkipMissing {
+		if _, ok := schema.getValue(); !ok {
+			return result
 		}
 	}
 
+	validated := schema.validate(opts)
+	for _, err := range validated.Errors {
+		err.prependPath(path...)
+		result.AddError(err)
+	}
+	result.addWarnings(validated.Warnings, path...)
+
 	return result
 }
@@ -0,0 +1,427 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+const (
+	RequiredEnumError     ValidationErrorType = "required_enum"
+	InvalidEnumValueError ValidationErrorType = "invalid_enum_value"
+	InvalidLiteralError   ValidationErrorType = "invalid_literal"
+)
+
+// EnumSchema implements the Schema interface for values that must be one of a
+// fixed set, like `"celsius" | "fahrenheit"`. A literal is an enum with a
+// single value.
+type EnumSchema[T comparable] struct {
+	// values are the allowed values
+	values []T
+
+	// isLiteral denotes the schema was created through Literal and compiles
+	// to "const" instead of "enum"
+	isLiteral bool
+
+	value *T
+
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[T]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
+
+	// refinements are the custom validations of the value
+	refinements []refinement[T]
+
+	// preprocessors rewrite the JSON value before it's decoded
+	preprocessors []preprocessFunc
+
+	description *string
+
+	// isOptional denotes if the value in the schema is optional
+	isOptional bool
+
+	// the result of the last validation
+	result *ValidationResult
+}
+
+// Enum creates a new schema that only accepts one of the given values
+func Enum[T comparable](values ...T) *EnumSchema[T] {
+	if len(values) == 0 {
+		panic("enum requires at least one value")
+	}
+
+	return &EnumSchema[T]{
+		values: values,
+		result: &ValidationResult{},
+	}
+}
+
+// Literal creates a new schema that only accepts the given value
+func Literal[T comparable](value T) *EnumSchema[T] {
+	e := Enum(value)
+	e.isLiteral = true
+	return e
+}
+
+// Values returns the allowed values
+func (e *EnumSchema[T]) Values() []T {
+	return e.values
+}
+
+// Refine adds a custom validation. It only runs for allowed values.
+func (e *EnumSchema[T]) Refine(check func(T) bool, opts ...ValidationOptions) *EnumSchema[T] {
+	e.refinements = append(e.refinements, newRefinement(check, opts))
+	return e
+}
+
+// Preprocess adds a function that rewrites the decoded JSON value before it's
+// decoded as one of the values, e.g. to lowercase strings
+func (e *EnumSchema[T]) Preprocess(fn func(interface{}) interface{}) *EnumSchema[T] {
+	e.preprocessors = append(e.preprocessors, fn)
+	return e
+}
+
+// Description sets the description of the enum
+func (e *EnumSchema[T]) Description(val string) *EnumSchema[T] {
+	e.description = &val
+	return e
+}
+
+// Nullable marks JSON null as a valid value of the value. Unlike Optional,
+// the key must still be present unless the value is optional as well.
+func (e *EnumSchema[T]) Nullable() *EnumSchema[T] {
+	e.isNullable = true
+	return e
+}
+
+// IsNullable implements Schema.IsNullable
+func (e *EnumSchema[T]) IsNullable() bool {
+	return e.isNullable
+}
+
+// State implements Schema.State
+func (e *EnumSchema[T]) State() ValueState {
+	_, ok := e.getValue()
+	return valueState(e.isNull, ok)
+}
+
+// Default marks the enum field as optional and sets the value it takes when
+// it's missing
+func (e *EnumSchema[T]) Default(v T) *EnumSchema[T] {
+	e.defaultValue = newDefault(v)
+	e.isOptional = true
+	return e
+}
+
+// DefaultFunc is Default with a value built by fn for every missing enum
+func (e *EnumSchema[T]) DefaultFunc(fn func() T) *EnumSchema[T] {
+	e.defaultValue = newDefaultFunc(fn)
+	e.isOptional = true
+	return e
+}
+
+// Optional marks the enum field as optional
+func (e *EnumSchema[T]) Optional() *EnumSchema[T] {
+	e.isOptional = true
+	return e
+}
+
+// IsOptional implements Schema.IsOptional
+func (e *EnumSchema[T]) IsOptional() bool {
+	return e.isOptional
+}
+
+func (e *EnumSchema[T]) Set(v T) *EnumSchema[T] {
+	e.isNull = false
+	e.value = &v
+	return e
+}
+
+func (e *EnumSchema[T]) setValue(val interface{}) error {
+	if val == nil && e.isNullable {
+		e.value = nil
+		e.isNull = true
+		return nil
+	}
+	e.isNull = false
+
+	v, ok := val.(T)
+	if !ok {
+		return fmt.Errorf("expected %T value, got %T", *new(T), val)
+	}
+	e.value = &v
+	return nil
+}
+
+// Value returns the enum value. This method returns the zero value and false
+// if the value hasn't been set
+func (e *EnumSchema[T]) Value() (T, bool) {
+	val, ok := e.getValue()
+	if !ok || val == nil {
+		var zero T
+		return zero, false
+	}
+	enumVal, ok := val.(T)
+	if !ok {
+		panic(fmt.Sprintf("EnumSchema: invalid internal value type %T, expected %T", val, *new(T)))
+	}
+	return enumVal, true
+}
+
+func (e *EnumSchema[T]) getValue() (interface{}, bool) {
+	if e.isNull {
+		return nil, true
+	}
+
+	if e.value == nil {
+		return nil, false
+	}
+	return *e.value, true
+}
+
+// Validate performs the validation
+func (e *EnumSchema[T]) Validate() *ValidationResult {
+	return e.validate(&ParseOptions{})
+}
+
+func (e *EnumSchema[T]) validate(opts *ParseOptions) *ValidationResult {
+	e.result = &ValidationResult{}
+
+	if e.isNull {
+		if !e.isNullable {
+			e.result.AddError(newNullError())
+		}
+		return e.result
+	}
+
+	val, ok := e.Value()
+	if !ok {
+		if !e.isOptional {
+			e.result.AddError(&ValidationError{
+				Type:    RequiredEnumError,
+				Message: "value has not been set",
+			})
+		}
+		return e.result
+	}
+
+	if e.contains(val) {
+		runRefinements(e.refinements, val, e.result, opts)
+		return e.result
+	}
+
+	if e.isLiteral {
+		e.result.AddError(&ValidationError{
+			Type:     InvalidLiteralError,
+			Message:  fmt.Sprintf("must be %s", formatEnumValue(e.values[0])),
+			Expected: e.values[0],
+			Actual:   val,
+		})
+		return e.result
+	}
+
+	e.result.AddError(&ValidationError{
+		Type:     InvalidEnumValueError,
+		Message:  fmt.Sprintf("must be one of %s", e.formatValues()),
+		Expected: e.values,
+		Actual:   val,
+	})
+
+	return e.result
+}
+
+// contains reports whether v is one of the allowed values
+func (e *EnumSchema[T]) contains(v T) bool {
+	for _, allowed := range e.values {
+		if allowed == v {
+			return true
+		}
+	}
+	return false
+}
+
+// formatValues formats the allowed values for error messages
+func (e *EnumSchema[T]) formatValues() string {
+	formatted := make([]string, len(e.values))
+	for i, v := range e.values {
+		formatted[i] = formatEnumValue(v)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// formatEnumValue formats a single value the way it's written in JSON
+func formatEnumValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// MarshalJSON implements json.Marshaler
+func (e *EnumSchema[T]) MarshalJSON() ([]byte, error) {
+	if e.isNull {
+		return json.Marshal(nil)
+	}
+
+	if e.value == nil {
+		if e.isOptional {
+			return json.Marshal(nil)
+		}
+		return nil, fmt.Errorf("required field has no value")
+	}
+	return json.Marshal(*e.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (e *EnumSchema[T]) UnmarshalJSON(data []byte) error {
+	if err := e.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	if result := e.Validate(); result.HasErrors() {
+		return result.Error()
+	}
+
+	return nil
+}
+
+func (e *EnumSchema[T]) decode(data []byte, _ *ParseOptions) error {
+	data, err := preprocess(data, e.preprocessors)
+	if err != nil {
+		return fmt.Errorf("invalid enum value: %w", err)
+	}
+
+	e.isNull = string(data) == "null"
+
+	if len(data) == 0 || string(data) == "null" {
+		e.value = nil
+		applyDefault(e, e.defaultValue)
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("invalid enum value: %w", err)
+	}
+
+	e.value = &v
+
+	return nil
+}
+
+// CompileJSONSchema implements Schema.CompileJSONSchema
+func (e *EnumSchema[T]) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error {
+	if e == nil {
+		return fmt.Errorf("found nil schema interface with JSON tag: %s", jsonTag)
+	}
+
+	propertySchema := &jsonschema.JSONSchema{
+		Type: jsonSchemaTypes(e.jsonSchemaType(), e.isNullable),
+	}
+
+	if e.description != nil {
+		propertySchema.Description = *e.description
+	}
+
+	propertySchema.Default = e.defaultValue.jsonSchemaDefault()
+
+	// A nullable literal has two values so it compiles to "enum" as well
+	if e.isLiteral && !e.isNullable {
+		propertySchema.SetConst(e.values[0])
+	} else {
+		propertySchema.Enum = make([]interface{}, len(e.values))
+		for i, v := range e.values {
+			propertySchema.Enum[i] = v
+		}
+		if e.isNullable {
+			propertySchema.Enum = append(propertySchema.Enum, nil)
+		}
+	}
+
+	if !e.isOptional {
+		schema.Required = append(schema.Required, jsonTag)
+	}
+
+	schema.Properties[jsonTag] = propertySchema
+	return nil
+}
+
+// jsonSchemaType returns the JSON schema type shared by every allowed value or
+// an empty string if the values are of different types
+func (e *EnumSchema[T]) jsonSchemaType() string {
+	schemaType := ""
+	for i, v := range e.values {
+		valueType := jsonTypeOf(v)
+		if i > 0 && valueType != schemaType {
+			return ""
+		}
+		schemaType = valueType
+	}
+	return schemaType
+}
+
+// Clone implements Schema.Clone by creating a deep copy of the EnumSchema
+func (e *EnumSchema[T]) Clone() Schema {
+	clone := &EnumSchema[T]{
+		values:      make([]T, len(e.values)),
+		isLiteral:   e.isLiteral,
+		refinements: make([]refinement[T], len(e.refinements)),
+		isOptional:  e.isOptional,
+		result:      &ValidationResult{},
+
+		preprocessors: make([]preprocessFunc, len(e.preprocessors)),
+	}
+
+	copy(clone.preprocessors, e.preprocessors)
+
+	copy(clone.values, e.values)
+	copy(clone.refinements, e.refinements)
+
+	if e.value != nil {
+		val := *e.value
+		clone.value = &val
+	}
+	if e.description != nil {
+		desc := *e.description
+		clone.description = &desc
+	}
+
+	clone.defaultValue = e.defaultValue
+	clone.isNullable = e.isNullable
+	clone.isNull = e.isNull
+
+	return clone
+}
+
+// jsonTypeOf returns the JSON schema type of a Go value
+func jsonTypeOf(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.String:
+		return StringSchemaType
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return IntegerSchemaType
+	case reflect.Float32, reflect.Float64:
+		return NumberSchemaType
+	case reflect.Slice, reflect.Array:
+		return ArraySchemaType
+	case reflect.Map, reflect.Struct:
+		return ObjectSchemaType
+	default:
+		return ""
+	}
+}
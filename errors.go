@@ -1,17 +1,98 @@
 package gsv
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // ValidationErrorType represents the specific type of validation error
 type ValidationErrorType string
 
 // ValidationError represents a single validation error with strong typing
 type ValidationError struct {
 	Type     ValidationErrorType
-	Field    string      // The field path where the error occurred
-	Message  string      // Human readable message
-	Expected interface{} // The expected value/constraint
-	Actual   interface{} // The actual value that failed validation
+	Field    string        // The JSON Pointer (RFC 6901) of the field where the error occurred
+	Path     []interface{} // The segments of Field: object keys (string) and array indices (int)
+	Message  string        // Human readable message
+	Expected interface{}   // The expected value/constraint
+	Actual   interface{}   // The actual value that failed validation
+
+	// Causes are the errors that led to this one, e.g. the errors of every
+	// variant of a union that didn't match. Their paths are relative to the
+	// root like the path of the error itself.
+	Causes []*ValidationError
 
 	// Could add more structured fields like:
 	// Constraint interface{} // The specific constraint that failed
 	// Metadata   map[string]interface{} // Any additional error context
 }
+
+// DottedPath returns the path of the field in a human readable form, e.g.
+// "deeper.deeper_score" or "items[3].name"
+func (e *ValidationError) DottedPath() string {
+	var b strings.Builder
+
+	for _, segment := range e.Path {
+		switch s := segment.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", s)
+
+		case string:
+			if strings.ContainsAny(s, ".[]") || s == "" {
+				fmt.Fprintf(&b, "[%q]", s)
+				continue
+			}
+
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(s)
+		}
+	}
+
+	return b.String()
+}
+
+// prependPath prefixes the path of the error with the path of its parent and
+// updates Field accordingly
+func (e *ValidationError) prependPath(parent ...interface{}) {
+	if len(parent) == 0 {
+		return
+	}
+
+	e.Path = appendPath(parent, e.Path...)
+	e.Field = jsonPointer(e.Path)
+
+	for _, cause := range e.Causes {
+		cause.prependPath(parent...)
+	}
+}
+
+// appendPath returns a new path with the segments appended to path. The
+// original path is never modified so it can be shared between siblings.
+func appendPath(path []interface{}, segments ...interface{}) []interface{} {
+	joined := make([]interface{}, 0, len(path)+len(segments))
+	joined = append(joined, path...)
+	return append(joined, segments...)
+}
+
+// jsonPointer builds the RFC 6901 JSON Pointer for the path segments
+func jsonPointer(path []interface{}) string {
+	var b strings.Builder
+
+	for _, segment := range path {
+		b.WriteByte('/')
+
+		switch s := segment.(type) {
+		case int:
+			b.WriteString(strconv.Itoa(s))
+
+		case string:
+			s = strings.ReplaceAll(s, "~", "~0")
+			b.WriteString(strings.ReplaceAll(s, "/", "~1"))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,915 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+// jsonSchemaAnnotations are the keywords that don't validate, along with the
+// definitions references point to
+var jsonSchemaAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+	"$defs": true, "definitions": true,
+}
+
+// fromJSONSchemaKeywords are the validation keywords FromJSONSchema
+// understands
+var fromJSONSchemaKeywords = map[string]bool{
+	// References
+	"$ref": true,
+
+	// Validators
+	"type": true, "enum": true, "const": true, "anyOf": true, "oneOf": true, "allOf": true,
+	"properties": true, "required": true, "additionalProperties": true, "propertyNames": true,
+	"minProperties": true, "maxProperties": true,
+	"minLength": true, "maxLength": true, "pattern": true, "format": true,
+	"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true, "multipleOf": true,
+	"items": true, "minItems": true, "maxItems": true, "uniqueItems": true,
+
+	// Reported when they're built
+	"not": true, "patternProperties": true,
+}
+
+// UnsupportedKeywordsError is returned by FromJSONSchema when the JSON schema
+// has keywords that can't be built into a gsv schema
+type UnsupportedKeywordsError struct {
+	// Keywords describe every unsupported keyword, prefixed with the JSON
+	// pointer of its schema
+	Keywords []string
+}
+
+func (e *UnsupportedKeywordsError) Error() string {
+	return fmt.Sprintf("unsupported JSON schema keywords: %s", strings.Join(e.Keywords, "; "))
+}
+
+// FromJSONSchema builds a gsv schema from a JSON schema, the inverse of
+// CompileSchema. It's meant for schemas that are only known at runtime, like
+// the input schemas of MCP tools, so values can be validated with gsv's
+// errors.
+//
+// Local references to the root, "definitions" and "$defs" are resolved.
+// Annotations, like "title" and "default", are ignored and so are formats
+// gsv doesn't validate, as the JSON schema spec allows. Every other keyword
+// that can't be built, like "not", is reported through an
+// UnsupportedKeywordsError. So are the validation keywords next to "const",
+// "enum", "anyOf" or "oneOf", which the built schema would drop, other than a
+// "type" every const or enum value has.
+//
+// Integers are built as Int64 schemas, numbers as Float64 schemas and
+// values of any type, like the ones of the empty schema, as Any schemas.
+// Untyped schemas with keywords of some types, like "properties", are built
+// as a union of every type.
+// "oneOf" is built like "anyOf" unless its variants are objects with a
+// shared discriminator, which are built as a DiscriminatedUnion.
+func FromJSONSchema(data []byte) (Schema, error) {
+	var root jsonschema.JSONSchema
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("could not unmarshal json schema: %w", err)
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("could not unmarshal json schema: %w", err)
+	}
+
+	// The root is being built while its nested schemas are
+	b := &schemaBuilder{root: &root, resolving: map[string]bool{"#": true}}
+	b.checkKeywords(raw, nil)
+
+	schema := b.build(&root, nil)
+
+	if len(b.unsupported) > 0 {
+		return nil, &UnsupportedKeywordsError{Keywords: b.unsupported}
+	}
+
+	return schema, nil
+}
+
+// schemaBuilder builds gsv schemas from a JSON schema tree
+type schemaBuilder struct {
+	root *jsonschema.JSONSchema
+
+	// resolving holds the references being built to detect recursion
+	resolving map[string]bool
+
+	unsupported []string
+}
+
+// unsupportedKeyword records a keyword of the schema at path
+func (b *schemaBuilder) unsupportedKeyword(path []interface{}, keyword string) {
+	pointer := jsonPointer(path)
+	if pointer == "" {
+		pointer = "/"
+	}
+	b.unsupported = append(b.unsupported, fmt.Sprintf("%s: %s", pointer, keyword))
+}
+
+// checkKeywords reports the keywords of the raw schema, and the schemas
+// nested in it, that FromJSONSchema doesn't know
+func (b *schemaBuilder) checkKeywords(raw interface{}, path []interface{}) {
+	schema, ok := raw.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, key := range sortedKeys(schema) {
+		value := schema[key]
+
+		switch key {
+		case "properties", "definitions", "$defs", "patternProperties":
+			if nested, ok := value.(map[string]interface{}); ok {
+				for _, name := range sortedKeys(nested) {
+					b.checkKeywords(nested[name], appendPath(path, key, name))
+				}
+			}
+
+		case "items", "additionalProperties", "propertyNames", "not":
+			b.checkKeywords(value, appendPath(path, key))
+
+		case "anyOf", "oneOf", "allOf":
+			if nested, ok := value.([]interface{}); ok {
+				for i, variant := range nested {
+					b.checkKeywords(variant, appendPath(path, key, i))
+				}
+			}
+
+		default:
+			if !jsonSchemaAnnotations[key] && !fromJSONSchemaKeywords[key] {
+				b.unsupportedKeyword(path, key)
+			}
+		}
+	}
+}
+
+// build builds the gsv schema for the JSON schema at path
+func (b *schemaBuilder) build(s *jsonschema.JSONSchema, path []interface{}) Schema {
+	if s == nil {
+		return Any()
+	}
+
+	if allowed, ok := s.IsBool(); ok {
+		if !allowed {
+			b.unsupportedKeyword(path, "false schema")
+		}
+		return Any()
+	}
+
+	if s.Ref != "" {
+		return b.buildRef(s, path)
+	}
+
+	if s.Not != nil {
+		b.unsupportedKeyword(path, "not")
+	}
+	if len(s.PatternProperties) > 0 {
+		b.unsupportedKeyword(path, "patternProperties")
+	}
+
+	var schema Schema
+	switch {
+	case s.HasConst():
+		b.checkSiblings(s, "const", []interface{}{s.Const}, path)
+		schema = b.buildEnum([]interface{}{s.Const}, true, path)
+	case s.Enum != nil && len(s.Enum) == 0:
+		b.unsupportedKeyword(path, "enum without values")
+		return Any()
+	case len(s.Enum) > 0:
+		b.checkSiblings(s, "enum", s.Enum, path)
+		schema = b.buildEnum(s.Enum, false, path)
+	case len(s.AnyOf) > 0:
+		b.checkSiblings(s, "anyOf", nil, path)
+		schema = b.buildUnion(s.AnyOf, "anyOf", path)
+	case len(s.OneOf) > 0:
+		b.checkSiblings(s, "oneOf", nil, path)
+		schema = b.buildUnion(s.OneOf, "oneOf", path)
+	default:
+		schema = b.buildTypes(s, path)
+	}
+
+	if s.Description != "" {
+		describe(schema, s.Description)
+	}
+
+	return schema
+}
+
+// checkSiblings reports the validation keywords next to the keyword the
+// schema is built from, which the built schema would drop. A type is kept if
+// every value of the const or enum has it.
+func (b *schemaBuilder) checkSiblings(s *jsonschema.JSONSchema, keyword string, values []interface{}, path []interface{}) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		b.unsupportedKeyword(path, fmt.Sprintf("%s: %s", keyword, err))
+		return
+	}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		b.unsupportedKeyword(path, fmt.Sprintf("%s: %s", keyword, err))
+		return
+	}
+
+	for _, key := range sortedKeys(keywords) {
+		switch {
+		case key == keyword || jsonSchemaAnnotations[key]:
+			continue
+
+		// Reported by build already
+		case key == "not" || key == "patternProperties":
+			continue
+
+		case key == "type" && values != nil && valuesHaveTypes(values, s.Type):
+			continue
+		}
+
+		b.unsupportedKeyword(path, fmt.Sprintf("%s alongside %s", key, keyword))
+	}
+}
+
+// valuesHaveTypes reports whether every value is of one of the types
+func valuesHaveTypes(values []interface{}, types jsonschema.TypeList) bool {
+	for _, v := range values {
+		typ := jsonTypeOf(v)
+		if types.Has(typ) {
+			continue
+		}
+		if f, ok := v.(float64); ok && f == math.Trunc(f) && types.Has(IntegerSchemaType) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// buildRef builds the schema the local reference points to
+func (b *schemaBuilder) buildRef(s *jsonschema.JSONSchema, path []interface{}) Schema {
+	target, err := b.root.Resolve(s.Ref)
+	if err != nil {
+		b.unsupportedKeyword(path, fmt.Sprintf("$ref %q: %s", s.Ref, err))
+		return Any()
+	}
+
+	// gsv schemas are built eagerly so recursive schemas can't be built
+	if b.resolving[s.Ref] {
+		b.unsupportedKeyword(path, fmt.Sprintf("recursive $ref %q", s.Ref))
+		return Any()
+	}
+
+	b.resolving[s.Ref] = true
+	defer delete(b.resolving, s.Ref)
+
+	schema := b.build(target, path)
+	if s.Description != "" {
+		describe(schema, s.Description)
+	}
+
+	return schema
+}
+
+// buildTypes builds the schema for the types of the JSON schema. A schema
+// without a type accepts any value, which is a union of every type when it
+// has keywords that only apply to some of them.
+func (b *schemaBuilder) buildTypes(s *jsonschema.JSONSchema, path []interface{}) Schema {
+	types := s.Type
+	if len(types) == 0 {
+		types = impliedTypes(s)
+	}
+
+	var (
+		nullable bool
+		variants []Schema
+	)
+	for _, typ := range types {
+		if typ == NullSchemaType {
+			nullable = true
+			continue
+		}
+		variants = append(variants, b.buildType(s, typ, path))
+	}
+
+	if len(s.AllOf) > 0 && !stringAllOf(s) {
+		b.unsupportedKeyword(path, "allOf")
+	}
+
+	switch len(variants) {
+	case 0:
+		if nullable {
+			return Enum[interface{}](nil).Nullable()
+		}
+		return Any()
+
+	case 1:
+		if nullable {
+			makeNullable(variants[0])
+		}
+		return variants[0]
+
+	default:
+		union := Union(variants...)
+		if nullable {
+			union.Nullable()
+		}
+		return union
+	}
+}
+
+// impliedTypes returns the types of an untyped schema. Keywords of a type
+// don't restrict the values of the other types, so an untyped schema with
+// any of them is built for every type, each with the keywords of its own.
+func impliedTypes(s *jsonschema.JSONSchema) jsonschema.TypeList {
+	if s.Properties == nil && s.AdditionalProperties == nil && s.PropertyNames == nil &&
+		s.MinProperties == nil && s.MaxProperties == nil && len(s.Required) == 0 &&
+		s.Items == nil && s.MinItems == nil && s.MaxItems == nil && s.UniqueItems == nil &&
+		s.MinLength == nil && s.MaxLength == nil && s.Pattern == "" && s.Format == "" && len(s.AllOf) == 0 &&
+		s.Minimum == nil && s.Maximum == nil && s.ExclusiveMinimum == nil &&
+		s.ExclusiveMaximum == nil && s.MultipleOf == nil {
+		return nil
+	}
+
+	return jsonschema.Types(ObjectSchemaType, ArraySchemaType, StringSchemaType,
+		NumberSchemaType, "boolean", NullSchemaType)
+}
+
+// buildType builds the schema of a single type
+func (b *schemaBuilder) buildType(s *jsonschema.JSONSchema, typ string, path []interface{}) Schema {
+	switch typ {
+	case StringSchemaType:
+		return b.buildString(s, path)
+	case IntegerSchemaType:
+		return b.buildInteger(s, path)
+	case NumberSchemaType:
+		return b.buildNumber(s, path)
+	case "boolean":
+		return Bool()
+	case ArraySchemaType:
+		return b.buildArray(s, path)
+	case ObjectSchemaType:
+		return b.buildObject(s, path)
+	default:
+		b.unsupportedKeyword(path, fmt.Sprintf("type %q", typ))
+		return Any()
+	}
+}
+
+// buildString builds a string schema with its length, patterns and formats.
+// The extra patterns and formats CompileSchema writes to "allOf" are built
+// as well.
+func (b *schemaBuilder) buildString(s *jsonschema.JSONSchema, path []interface{}) Schema {
+	str := String()
+
+	if b.nonNegative(s.MinLength, "minLength", path) {
+		str.Min(*s.MinLength)
+	}
+	if b.nonNegative(s.MaxLength, "maxLength", path) {
+		str.Max(*s.MaxLength)
+	}
+
+	constraints := []*jsonschema.JSONSchema{s}
+	if stringAllOf(s) {
+		constraints = append(constraints, s.AllOf...)
+	}
+
+	for _, c := range constraints {
+		if c.Pattern != "" {
+			if _, err := regexp.Compile(c.Pattern); err != nil {
+				b.unsupportedKeyword(path, fmt.Sprintf("pattern %q", c.Pattern))
+			} else {
+				str.Regex(c.Pattern)
+			}
+		}
+
+		if format, ok := stringFormats[c.Format]; ok {
+			format(str)
+		}
+	}
+
+	return str
+}
+
+// nonNegative reports whether the count keyword is set and can be built,
+// recording it as unsupported when it's negative
+func (b *schemaBuilder) nonNegative(v *int, keyword string, path []interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if *v < 0 {
+		b.unsupportedKeyword(path, fmt.Sprintf("%s %d", keyword, *v))
+		return false
+	}
+	return true
+}
+
+// stringFormats apply the formats gsv validates to a string schema
+var stringFormats = map[string]func(*StringSchema){
+	"email":     func(s *StringSchema) { s.Email() },
+	"uri":       func(s *StringSchema) { s.URL() },
+	"uuid":      func(s *StringSchema) { s.UUID() },
+	"ipv4":      func(s *StringSchema) { s.IPv4() },
+	"ipv6":      func(s *StringSchema) { s.IPv6() },
+	"hostname":  func(s *StringSchema) { s.Hostname() },
+	"date-time": func(s *StringSchema) { s.DateTime() },
+	"date":      func(s *StringSchema) { s.Date() },
+	"duration":  func(s *StringSchema) { s.Duration() },
+}
+
+// stringAllOf reports whether "allOf" only holds string patterns and
+// formats, the way CompileSchema writes them
+func stringAllOf(s *jsonschema.JSONSchema) bool {
+	for _, c := range s.AllOf {
+		if !stringAllOfEntry(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// stringAllOfEntry reports whether an "allOf" entry only holds a string
+// pattern or format
+func stringAllOfEntry(c *jsonschema.JSONSchema) bool {
+	return c != nil && reflect.DeepEqual(*c, jsonschema.JSONSchema{Pattern: c.Pattern, Format: c.Format})
+}
+
+// buildInteger builds an Int64 schema with its bounds
+func (b *schemaBuilder) buildInteger(s *jsonschema.JSONSchema, path []interface{}) Schema {
+	n := Int64()
+
+	if s.Minimum != nil {
+		n.Min(int64Bound(math.Ceil(*s.Minimum)))
+	}
+	if s.Maximum != nil {
+		n.Max(int64Bound(math.Floor(*s.Maximum)))
+	}
+	if s.ExclusiveMinimum != nil {
+		n.Gt(int64Bound(math.Floor(*s.ExclusiveMinimum)))
+	}
+	if s.ExclusiveMaximum != nil {
+		n.Lt(int64Bound(math.Ceil(*s.ExclusiveMaximum)))
+	}
+	if s.MultipleOf != nil {
+		if *s.MultipleOf != math.Trunc(*s.MultipleOf) || *s.MultipleOf <= 0 {
+			b.unsupportedKeyword(path, fmt.Sprintf("multipleOf %v for integers", *s.MultipleOf))
+		} else {
+			n.MultipleOf(int64(*s.MultipleOf))
+		}
+	}
+
+	return n
+}
+
+// int64Bound converts a bound of an integer schema to an int64, clamping it
+// to the int64 range
+func int64Bound(v float64) int64 {
+	switch {
+	case v >= math.MaxInt64:
+		return math.MaxInt64
+	case v <= math.MinInt64:
+		return math.MinInt64
+	default:
+		return int64(v)
+	}
+}
+
+// buildNumber builds a Float64 schema with its bounds
+func (b *schemaBuilder) buildNumber(s *jsonschema.JSONSchema, path []interface{}) Schema {
+	n := Float64()
+
+	if s.Minimum != nil {
+		n.Min(*s.Minimum)
+	}
+	if s.Maximum != nil {
+		n.Max(*s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil {
+		n.Gt(*s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil {
+		n.Lt(*s.ExclusiveMaximum)
+	}
+	if s.MultipleOf != nil {
+		if *s.MultipleOf <= 0 {
+			b.unsupportedKeyword(path, fmt.Sprintf("multipleOf %v", *s.MultipleOf))
+		} else {
+			n.MultipleOf(*s.MultipleOf)
+		}
+	}
+
+	return n
+}
+
+// buildArray builds an array schema with the bounds on its items
+func (b *schemaBuilder) buildArray(s *jsonschema.JSONSchema, path []interface{}) Schema {
+	array := Array(b.build(s.Items, appendPath(path, "items")))
+
+	if b.nonNegative(s.MinItems, "minItems", path) {
+		array.MinItems(*s.MinItems)
+	}
+	if b.nonNegative(s.MaxItems, "maxItems", path) {
+		array.MaxItems(*s.MaxItems)
+	}
+	if s.UniqueItems != nil && *s.UniqueItems {
+		array.Refine(uniqueItems, ValidationOptions{Message: "must not have duplicate items"})
+	}
+
+	return array
+}
+
+// uniqueItems reports whether every item is different from the others
+func uniqueItems(items []interface{}) bool {
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if reflect.DeepEqual(items[i], items[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// buildObject builds an object schema for objects with properties and a
+// record schema for objects with dynamic keys
+func (b *schemaBuilder) buildObject(s *jsonschema.JSONSchema, path []interface{}) Schema {
+	allowed, isBool := s.AdditionalProperties.IsBool()
+
+	// Objects without properties or required keys are records if their keys
+	// or values are constrained. Without additional properties they can only
+	// be empty, which the object schema enforces.
+	if len(s.Properties) == 0 && len(s.Required) == 0 && !(isBool && !allowed) &&
+		((s.AdditionalProperties != nil && !isBool) || s.PropertyNames != nil) {
+		key := Schema(String())
+		if s.PropertyNames != nil {
+			key = b.build(s.PropertyNames, appendPath(path, "propertyNames"))
+		}
+
+		var value Schema = Any()
+		if s.AdditionalProperties != nil && !isBool {
+			value = b.build(s.AdditionalProperties, appendPath(path, "additionalProperties"))
+		}
+
+		record := Record(key, value)
+		if b.nonNegative(s.MinProperties, "minProperties", path) {
+			record.MinProperties(*s.MinProperties)
+		}
+		if b.nonNegative(s.MaxProperties, "maxProperties", path) {
+			record.MaxProperties(*s.MaxProperties)
+		}
+		return record
+	}
+
+	return b.buildObjectSchema(s, path, allowed, isBool)
+}
+
+// buildObjectSchema builds an object schema. Required keys without a property
+// schema accept any value.
+func (b *schemaBuilder) buildObjectSchema(s *jsonschema.JSONSchema, path []interface{}, allowed, isBool bool) *ObjectSchema {
+	required := make(map[string]bool, len(s.Required))
+	for _, key := range s.Required {
+		required[key] = true
+	}
+
+	shape := make(map[string]Schema, len(s.Properties))
+	for _, key := range sortedKeys(s.Properties) {
+		property := b.build(s.Properties[key], appendPath(path, "properties", key))
+		if !required[key] {
+			makeOptional(property)
+		}
+		shape[key] = property
+	}
+	for _, key := range s.Required {
+		if _, ok := shape[key]; !ok {
+			shape[key] = Any()
+		}
+	}
+
+	object := Object(shape)
+
+	switch {
+	case isBool && !allowed:
+		object.Strict()
+	case s.AdditionalProperties != nil && !isBool:
+		object.Catchall(b.build(s.AdditionalProperties, appendPath(path, "additionalProperties")))
+	default:
+		object.Passthrough()
+	}
+
+	// Objects that can only be empty have no names to check
+	if s.PropertyNames != nil && !(isBool && !allowed && len(shape) == 0) {
+		b.unsupportedKeyword(path, "propertyNames for objects with properties")
+	}
+
+	if b.nonNegative(s.MinProperties, "minProperties", path) {
+		min := *s.MinProperties
+		object.Refine(func(v map[string]interface{}) bool { return len(v) >= min },
+			ValidationOptions{Message: fmt.Sprintf("must have at least %d properties", min)})
+	}
+	if b.nonNegative(s.MaxProperties, "maxProperties", path) {
+		max := *s.MaxProperties
+		object.Refine(func(v map[string]interface{}) bool { return len(v) <= max },
+			ValidationOptions{Message: fmt.Sprintf("must have at most %d properties", max)})
+	}
+
+	return object
+}
+
+// buildEnum builds an enum of the values, typed after their JSON type. Null
+// makes the enum nullable.
+func (b *schemaBuilder) buildEnum(values []interface{}, literal bool, path []interface{}) Schema {
+	var (
+		nullable bool
+		kept     []interface{}
+		types    = make(map[string]bool)
+	)
+	for _, v := range values {
+		switch v.(type) {
+		case nil:
+			nullable = true
+			continue
+		case map[string]interface{}, []interface{}:
+			b.unsupportedKeyword(path, "enum values that are objects or arrays")
+			return Any()
+		}
+
+		kept = append(kept, v)
+		types[jsonTypeOf(v)] = true
+	}
+
+	if len(kept) == 0 {
+		return Enum[interface{}](nil).Nullable()
+	}
+
+	var schema Schema
+	switch {
+	case len(types) > 1:
+		schema = newEnum(kept, literal, func(v interface{}) interface{} { return v })
+	case types[StringSchemaType]:
+		schema = newEnum(kept, literal, func(v interface{}) string { return v.(string) })
+	case types[NumberSchemaType]:
+		schema = newEnum(kept, literal, func(v interface{}) float64 { return v.(float64) })
+	default:
+		schema = newEnum(kept, literal, func(v interface{}) bool { return v.(bool) })
+	}
+
+	if nullable {
+		makeNullable(schema)
+	}
+
+	return schema
+}
+
+// newEnum builds an enum, or a literal for a single const value, converting
+// the values to T
+func newEnum[T comparable](values []interface{}, literal bool, convert func(interface{}) T) Schema {
+	if literal {
+		return Literal(convert(values[0]))
+	}
+
+	typed := make([]T, len(values))
+	for i, v := range values {
+		typed[i] = convert(v)
+	}
+	return Enum(typed...)
+}
+
+// buildUnion builds a union of the variants. Null variants make the union
+// nullable and objects with a shared discriminator are built as a
+// discriminated union.
+func (b *schemaBuilder) buildUnion(variants []*jsonschema.JSONSchema, keyword string, path []interface{}) Schema {
+	var (
+		nullable bool
+		kept     []*jsonschema.JSONSchema
+		paths    [][]interface{}
+	)
+	for i, variant := range variants {
+		variantPath := appendPath(path, keyword, i)
+
+		resolved := variant
+		if variant != nil && variant.Ref != "" {
+			if target, err := b.root.Resolve(variant.Ref); err == nil {
+				resolved = target
+			}
+		}
+
+		if resolved != nil && len(resolved.Type) == 1 && resolved.Type[0] == NullSchemaType {
+			nullable = true
+			continue
+		}
+
+		kept = append(kept, variant)
+		paths = append(paths, variantPath)
+	}
+
+	var schema Schema
+	if discriminator := b.discriminator(kept); discriminator != "" {
+		objects := make(map[string]*ObjectSchema, len(kept))
+		for i, variant := range kept {
+			// Referenced variants are being built like in buildRef so they
+			// can't refer to themselves
+			ref := variant.Ref
+			if ref != "" {
+				if b.resolving[ref] {
+					b.unsupportedKeyword(paths[i], fmt.Sprintf("recursive $ref %q", ref))
+					continue
+				}
+				b.resolving[ref] = true
+			}
+
+			variant = b.deref(variant)
+			allowed, isBool := variant.AdditionalProperties.IsBool()
+			object := b.buildObjectSchema(variant, paths[i], allowed, isBool)
+			if variant.Description != "" {
+				object.Description(variant.Description)
+			}
+			objects[discriminatorValue(variant.Properties[discriminator])] = object
+
+			delete(b.resolving, ref)
+		}
+		schema = DiscriminatedUnion(discriminator, objects)
+	} else {
+		built := make([]Schema, len(kept))
+		for i, variant := range kept {
+			built[i] = b.build(variant, paths[i])
+		}
+
+		switch len(built) {
+		case 0:
+			return Enum[interface{}](nil).Nullable()
+		case 1:
+			schema = built[0]
+		default:
+			schema = Union(built...)
+		}
+	}
+
+	if nullable {
+		makeNullable(schema)
+	}
+
+	return schema
+}
+
+// deref returns the schema a local reference points to, or the schema itself
+func (b *schemaBuilder) deref(s *jsonschema.JSONSchema) *jsonschema.JSONSchema {
+	if s != nil && s.Ref != "" {
+		if target, err := b.root.Resolve(s.Ref); err == nil {
+			return target
+		}
+	}
+	return s
+}
+
+// discriminator returns the property every variant requires with a distinct
+// single string value, or an empty string if there's none
+func (b *schemaBuilder) discriminator(variants []*jsonschema.JSONSchema) string {
+	if len(variants) < 2 {
+		return ""
+	}
+
+	first := b.deref(variants[0])
+	if first == nil {
+		return ""
+	}
+
+	candidates := make([]string, 0, len(first.Properties))
+	for key := range first.Properties {
+		candidates = append(candidates, key)
+	}
+	sort.Strings(candidates)
+
+candidates:
+	for _, key := range candidates {
+		seen := make(map[string]bool, len(variants))
+
+		for _, variant := range variants {
+			variant = b.deref(variant)
+			if variant == nil || variant.Ref != "" || len(variant.AnyOf) > 0 || len(variant.OneOf) > 0 ||
+				!variant.Type.Has(ObjectSchemaType) || len(variant.Type) > 1 ||
+				(variant.AdditionalProperties != nil && !isBoolSchema(variant.AdditionalProperties)) {
+				return ""
+			}
+
+			value := discriminatorValue(variant.Properties[key])
+			if value == "" || seen[value] || !containsString(variant.Required, key) {
+				continue candidates
+			}
+			seen[value] = true
+		}
+
+		return key
+	}
+
+	return ""
+}
+
+// discriminatorValue returns the string a property only allows, through
+// "const" or a single value "enum", or an empty string
+func discriminatorValue(s *jsonschema.JSONSchema) string {
+	if s == nil {
+		return ""
+	}
+
+	value := s.Const
+	if value == nil && len(s.Enum) == 1 {
+		value = s.Enum[0]
+	}
+
+	str, _ := value.(string)
+	return str
+}
+
+func isBoolSchema(s *jsonschema.JSONSchema) bool {
+	_, ok := s.IsBool()
+	return ok
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// modifiable are the modifiers every schema FromJSONSchema builds has
+type modifiable[S any] interface {
+	Optional() S
+	Nullable() S
+	Description(string) S
+}
+
+// makeOptional marks a schema built by FromJSONSchema as optional
+func makeOptional(s Schema) {
+	modify(s, func(m modifier) { m.optional() })
+}
+
+// makeNullable marks a schema built by FromJSONSchema as nullable
+func makeNullable(s Schema) {
+	modify(s, func(m modifier) { m.nullable() })
+}
+
+// describe sets the description of a schema built by FromJSONSchema
+func describe(s Schema, description string) {
+	modify(s, func(m modifier) { m.description(description) })
+}
+
+// modifier applies the modifiers of a schema regardless of its type
+type modifier struct {
+	optional    func()
+	nullable    func()
+	description func(string)
+}
+
+// newModifier wraps the modifiers of a schema
+func newModifier[S any](s modifiable[S]) modifier {
+	return modifier{
+		optional:    func() { s.Optional() },
+		nullable:    func() { s.Nullable() },
+		description: func(d string) { s.Description(d) },
+	}
+}
+
+// modify applies fn to the modifiers of the schema
+func modify(s Schema, fn func(modifier)) {
+	switch s := s.(type) {
+	case *StringSchema:
+		fn(newModifier[*StringSchema](s))
+	case *Int64Schema:
+		fn(newModifier[*Int64Schema](s))
+	case *Float64Schema:
+		fn(newModifier[*Float64Schema](s))
+	case *BoolSchema:
+		fn(newModifier[*BoolSchema](s))
+	case *ArraySchema:
+		fn(newModifier[*ArraySchema](s))
+	case *ObjectSchema:
+		fn(newModifier[*ObjectSchema](s))
+	case *RecordSchema:
+		fn(newModifier[*RecordSchema](s))
+	case *UnionSchema:
+		fn(newModifier[*UnionSchema](s))
+	case *DiscriminatedUnionSchema:
+		fn(newModifier[*DiscriminatedUnionSchema](s))
+	case *EnumSchema[string]:
+		fn(newModifier[*EnumSchema[string]](s))
+	case *EnumSchema[float64]:
+		fn(newModifier[*EnumSchema[float64]](s))
+	case *EnumSchema[bool]:
+		fn(newModifier[*EnumSchema[bool]](s))
+	case *EnumSchema[interface{}]:
+		fn(newModifier[*EnumSchema[interface{}]](s))
+	case *AnySchema:
+		// Any already accepts null
+		fn(modifier{
+			optional:    func() { s.Optional() },
+			nullable:    func() {},
+			description: func(d string) { s.Description(d) },
+		})
+	}
+}
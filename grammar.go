@@ -0,0 +1,521 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+// grammarPrimitives are the GBNF rules shared by every grammar, following
+// the ones llama.cpp generates for JSON schemas
+var grammarPrimitives = map[string]string{
+	"space":         `| " " | "\n"{1,2} [ \t]{0,20}`,
+	"boolean":       `("true" | "false") space`,
+	"null":          `"null" space`,
+	"char":          `[^"\\\x7F\x00-\x1F] | [\\] (["\\bfnrt] | "u" [0-9a-fA-F]{4})`,
+	"string":        `"\"" char* "\"" space`,
+	"integral-part": `[0] | [1-9] [0-9]{0,15}`,
+	"decimal-part":  `[0-9]{1,16}`,
+	"integer":       `("-"? integral-part) space`,
+	"number":        `("-"? integral-part) ("." decimal-part)? ([eE] [-+]? integral-part)? space`,
+	"value":         `object | array | string | number | boolean | null`,
+	"object":        `"{" space ( string ":" space value ("," space string ":" space value)* )? "}" space`,
+	"array":         `"[" space ( value ("," space value)* )? "]" space`,
+}
+
+// grammarPrimitiveDeps are the primitives each primitive refers to
+var grammarPrimitiveDeps = map[string][]string{
+	"boolean": {"space"},
+	"null":    {"space"},
+	"string":  {"char", "space"},
+	"integer": {"integral-part", "space"},
+	"number":  {"integral-part", "decimal-part", "space"},
+	"value":   {"object", "array", "string", "number", "boolean", "null"},
+	"object":  {"string", "value", "space"},
+	"array":   {"value", "space"},
+}
+
+// grammarRuleName matches the characters that can't be part of a rule name
+var grammarRuleName = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// CompileGrammar converts a gsv schema struct into a GBNF grammar for the
+// constrained decoding of llama.cpp and the tools built on it, like Ollama.
+// A single schema, like an ObjectSchema, can be compiled as well.
+//
+// The grammar enforces the structure of the value: types, enums, string
+// lengths in code points, the number of array items and which properties are
+// required.
+// Constructs a grammar can't express, like numeric ranges and patterns, are
+// left to Parse. They're listed in a comment at the top of the grammar.
+// Objects only get their declared properties and in a fixed order: the
+// required ones in the order of the struct, then the optional ones sorted.
+func CompileGrammar(schema interface{}) ([]byte, error) {
+	compiled, err := compileJSONSchema(schema, &CompileSchemaOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	g := &grammarCompiler{
+		rules:       make(map[string]string),
+		primitives:  make(map[string]bool),
+		lengthUnits: make(map[*jsonschema.JSONSchema]bool),
+	}
+	g.collectLengthUnits(schema, compiled)
+
+	root := g.expr(compiled, "root", nil)
+	if root != "root" {
+		g.add("root", root)
+	}
+
+	return g.grammar(), nil
+}
+
+// grammarCompiler collects the rules of a grammar while walking the schema
+type grammarCompiler struct {
+	rules      map[string]string
+	names      []string
+	primitives map[string]bool
+
+	// fallbacks are the keywords left to Parse, prefixed with the JSON
+	// pointer of their schema
+	fallbacks []string
+
+	// lengthUnits are the compiled strings whose length isn't counted in code
+	// points, for which the grammar only enforces bounds
+	lengthUnits map[*jsonschema.JSONSchema]bool
+}
+
+// collectLengthUnits walks the gsv schema along with the JSON schema it
+// compiled to, recording the strings counted in bytes or graphemes
+func (g *grammarCompiler) collectLengthUnits(schema interface{}, compiled *jsonschema.JSONSchema) {
+	if compiled == nil {
+		return
+	}
+
+	s, ok := schema.(Schema)
+	if !ok {
+		val := reflect.Indirect(reflect.ValueOf(schema))
+		for i := 0; i < val.NumField(); i++ {
+			jsonTag, _, _ := strings.Cut(val.Type().Field(i).Tag.Get("json"), ",")
+			if jsonTag == "" || jsonTag == "-" {
+				continue
+			}
+			g.collectLengthUnits(val.Field(i).Interface(), compiled.Properties[jsonTag])
+		}
+		return
+	}
+
+	switch s := s.(type) {
+	case *StringSchema:
+		if s.lengthMode != runeLength {
+			g.lengthUnits[compiled] = true
+		}
+
+	case *ObjectSchema:
+		for key, shape := range s.shape {
+			g.collectLengthUnits(shape, compiled.Properties[key])
+		}
+		if s.mode == catchallUnknownKeys {
+			g.collectLengthUnits(s.catchall, compiled.AdditionalProperties)
+		}
+
+	case *ArraySchema:
+		g.collectLengthUnits(s.elementSchema, compiled.Items)
+
+	case *RecordSchema:
+		if s.keyPattern == nil {
+			g.collectLengthUnits(s.valueSchema, compiled.AdditionalProperties)
+		}
+
+	case *UnionSchema:
+		for i, variant := range s.variants {
+			g.collectLengthUnits(variant, compiled.AnyOf[i])
+		}
+
+	case *DiscriminatedUnionSchema:
+		for i, tag := range s.tags {
+			g.collectLengthUnits(s.variants[tag], compiled.OneOf[i])
+		}
+
+	case interface{ Inner() Schema }:
+		g.collectLengthUnits(s.Inner(), compiled)
+	}
+}
+
+// add adds a rule, renaming it if the name is taken, and returns its name
+func (g *grammarCompiler) add(name, body string) string {
+	name = strings.Trim(grammarRuleName.ReplaceAllString(name, "-"), "-")
+
+	unique := name
+	for i := 1; g.rules[unique] != "" || grammarPrimitives[unique] != ""; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	g.rules[unique] = body
+	g.names = append(g.names, unique)
+
+	return unique
+}
+
+// use marks the primitive and the primitives it refers to as used and
+// returns its name
+func (g *grammarCompiler) use(primitive string) string {
+	if !g.primitives[primitive] {
+		g.primitives[primitive] = true
+		for _, dep := range grammarPrimitiveDeps[primitive] {
+			g.use(dep)
+		}
+	}
+	return primitive
+}
+
+// fallback records a keyword the grammar doesn't enforce
+func (g *grammarCompiler) fallback(path []interface{}, keyword string) {
+	pointer := jsonPointer(path)
+	if pointer == "" {
+		pointer = "/"
+	}
+	g.fallbacks = append(g.fallbacks, fmt.Sprintf("%s: %s", pointer, keyword))
+}
+
+// expr returns the GBNF expression matching the schema. Schemas that need
+// more than a primitive get their own rule, named after name.
+func (g *grammarCompiler) expr(schema *jsonschema.JSONSchema, name string, path []interface{}) string {
+	if schema == nil {
+		return g.use("value")
+	}
+
+	if allowed, ok := schema.IsBool(); ok {
+		if !allowed {
+			g.fallback(path, "false schemas")
+		}
+		return g.use("value")
+	}
+
+	if schema.Not != nil {
+		g.fallback(path, "not")
+	}
+	if len(schema.AllOf) > 0 {
+		g.fallback(path, "allOf")
+	}
+
+	switch {
+	case schema.HasConst():
+		return g.add(name, g.literal(schema.Const))
+
+	case len(schema.Enum) > 0:
+		alternatives := make([]string, len(schema.Enum))
+		for i, v := range schema.Enum {
+			alternatives[i] = g.literal(v)
+		}
+		return g.add(name, strings.Join(alternatives, " | "))
+
+	case len(schema.AnyOf) > 0 || len(schema.OneOf) > 0:
+		if len(schema.OneOf) > 0 {
+			g.fallback(path, "oneOf matching exactly one variant")
+		}
+
+		variants := append(append([]*jsonschema.JSONSchema{}, schema.AnyOf...), schema.OneOf...)
+		alternatives := make([]string, len(variants))
+		for i, variant := range variants {
+			alternatives[i] = g.expr(variant, fmt.Sprintf("%s-%d", name, i), appendPath(path, "anyOf", i))
+		}
+		return g.add(name, strings.Join(alternatives, " | "))
+
+	case len(schema.Type) == 0:
+		return g.use("value")
+
+	case len(schema.Type) == 1:
+		return g.typeExpr(schema, schema.Type[0], name, path)
+
+	default:
+		alternatives := make([]string, len(schema.Type))
+		for i, typ := range schema.Type {
+			alternatives[i] = g.typeExpr(schema, typ, fmt.Sprintf("%s-%s", name, typ), path)
+		}
+		return g.add(name, strings.Join(alternatives, " | "))
+	}
+}
+
+// typeExpr returns the expression matching the schema's keywords for a
+// single type
+func (g *grammarCompiler) typeExpr(schema *jsonschema.JSONSchema, typ, name string, path []interface{}) string {
+	switch typ {
+	case StringSchemaType:
+		if schema.Pattern != "" {
+			g.fallback(path, "pattern")
+		}
+		if schema.Format != "" {
+			g.fallback(path, "format")
+		}
+
+		if schema.MinLength == nil && schema.MaxLength == nil {
+			return g.use("string")
+		}
+
+		// A char is a code point or an escape sequence, the way JSON schema
+		// counts lengths. The lengths are only bounds for strings counted in
+		// bytes or graphemes, so Parse checks them in the string's own unit.
+		if g.lengthUnits[schema] {
+			g.fallback(path, "length unit")
+		}
+
+		g.use("char")
+		g.use("space")
+		return g.add(name, fmt.Sprintf(`"\"" char%s "\"" space`, repetition(schema.MinLength, schema.MaxLength, 0)))
+
+	case IntegerSchemaType, NumberSchemaType:
+		if schema.Minimum != nil || schema.Maximum != nil ||
+			schema.ExclusiveMinimum != nil || schema.ExclusiveMaximum != nil {
+			g.fallback(path, "numeric ranges")
+		}
+		if schema.MultipleOf != nil {
+			g.fallback(path, "multipleOf")
+		}
+		return g.use(typ)
+
+	case "boolean":
+		return g.use("boolean")
+
+	case NullSchemaType:
+		return g.use("null")
+
+	case ArraySchemaType:
+		return g.arrayExpr(schema, name, path)
+
+	case ObjectSchemaType:
+		return g.objectExpr(schema, name, path)
+
+	default:
+		return g.use("value")
+	}
+}
+
+// arrayExpr returns the rule of an array with the bounds on its items
+func (g *grammarCompiler) arrayExpr(schema *jsonschema.JSONSchema, name string, path []interface{}) string {
+	if schema.UniqueItems != nil && *schema.UniqueItems {
+		g.fallback(path, "uniqueItems")
+	}
+
+	g.use("space")
+	item := g.expr(schema.Items, name+"-item", appendPath(path, "items"))
+
+	minItems, maxItems := 0, -1
+	if schema.MinItems != nil {
+		minItems = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		maxItems = *schema.MaxItems
+	}
+
+	// The items after the first one
+	var rest string
+	if maxItems < 0 || maxItems > 1 {
+		restMin := max(minItems-1, 0)
+		restMax := -1
+		if maxItems > 0 {
+			restMax = maxItems - 1
+		}
+		rest = fmt.Sprintf(` ("," space %s)%s`, item, repetitionOf(restMin, restMax))
+	}
+
+	switch {
+	case maxItems == 0:
+		return g.add(name, `"[" space "]" space`)
+	case minItems == 0:
+		return g.add(name, fmt.Sprintf(`"[" space (%s%s)? "]" space`, item, rest))
+	default:
+		return g.add(name, fmt.Sprintf(`"[" space %s%s "]" space`, item, rest))
+	}
+}
+
+// objectExpr returns the rule of an object. Objects with properties only get
+// those properties, objects without them, like records, get any key.
+func (g *grammarCompiler) objectExpr(schema *jsonschema.JSONSchema, name string, path []interface{}) string {
+	if len(schema.PatternProperties) > 0 {
+		g.fallback(path, "patternProperties")
+	}
+	if schema.PropertyNames != nil {
+		g.fallback(path, "propertyNames")
+	}
+	if schema.MinProperties != nil || schema.MaxProperties != nil {
+		g.fallback(path, "minProperties and maxProperties")
+	}
+
+	g.use("space")
+
+	allowed, isBool := schema.AdditionalProperties.IsBool()
+
+	if len(schema.Properties) == 0 {
+		if isBool && !allowed {
+			return g.add(name, `"{" space "}" space`)
+		}
+		if schema.AdditionalProperties == nil || isBool {
+			return g.use("object")
+		}
+
+		g.use("string")
+		value := g.expr(schema.AdditionalProperties, name+"-value", appendPath(path, "additionalProperties"))
+		kv := fmt.Sprintf(`string ":" space %s`, value)
+		return g.add(name, fmt.Sprintf(`"{" space (%s ("," space %s)*)? "}" space`, kv, kv))
+	}
+
+	if schema.AdditionalProperties != nil && !isBool {
+		g.fallback(path, "additionalProperties")
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, key := range schema.Required {
+		required[key] = true
+	}
+
+	var optional []string
+	for key := range schema.Properties {
+		if !required[key] {
+			optional = append(optional, key)
+		}
+	}
+	sort.Strings(optional)
+
+	// kv returns the rule of the key value pair of a property
+	kv := func(key string) string {
+		value := g.expr(schema.Properties[key], name+"-"+key, appendPath(path, "properties", key))
+		return g.add(name+"-"+key+"-kv", fmt.Sprintf(`%s space ":" space %s`, g.quoted(key), value))
+	}
+
+	var requiredKVs []string
+	for _, key := range schema.Required {
+		if _, ok := schema.Properties[key]; ok {
+			requiredKVs = append(requiredKVs, kv(key))
+		}
+	}
+
+	optionalKVs := make([]string, len(optional))
+	for i, key := range optional {
+		optionalKVs[i] = kv(key)
+	}
+
+	var body string
+	if len(requiredKVs) > 0 {
+		body = strings.Join(requiredKVs, ` "," space `)
+		for _, optionalKV := range optionalKVs {
+			body += fmt.Sprintf(` ("," space %s)?`, optionalKV)
+		}
+	} else {
+		// Any of the optional properties can come first
+		alternatives := make([]string, len(optionalKVs))
+		for i, first := range optionalKVs {
+			alternatives[i] = first
+			for _, next := range optionalKVs[i+1:] {
+				alternatives[i] += fmt.Sprintf(` ("," space %s)?`, next)
+			}
+		}
+		body = fmt.Sprintf("(%s)?", strings.Join(alternatives, " | "))
+	}
+
+	return g.add(name, fmt.Sprintf(`"{" space %s "}" space`, body))
+}
+
+// literal returns the expression matching the JSON encoding of v
+func (g *grammarCompiler) literal(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return gbnfLiteral(string(data)) + " " + g.use("space")
+}
+
+// quoted returns the GBNF literal matching the JSON encoding of the string
+func (g *grammarCompiler) quoted(s string) string {
+	data, _ := json.Marshal(s)
+	return gbnfLiteral(string(data))
+}
+
+// grammar renders every rule, starting with the root
+func (g *grammarCompiler) grammar() []byte {
+	var b strings.Builder
+
+	if len(g.fallbacks) > 0 {
+		b.WriteString("# Checked by Parse, not by the grammar:\n")
+		for _, fallback := range g.fallbacks {
+			fmt.Fprintf(&b, "#   %s\n", fallback)
+		}
+		b.WriteByte('\n')
+	}
+
+	fmt.Fprintf(&b, "root ::= %s\n", g.rules["root"])
+	for _, name := range g.names {
+		if name != "root" {
+			fmt.Fprintf(&b, "%s ::= %s\n", name, g.rules[name])
+		}
+	}
+
+	primitives := make([]string, 0, len(g.primitives))
+	for name := range g.primitives {
+		primitives = append(primitives, name)
+	}
+	sort.Strings(primitives)
+
+	for _, name := range primitives {
+		fmt.Fprintf(&b, "%s ::= %s\n", name, grammarPrimitives[name])
+	}
+
+	return []byte(b.String())
+}
+
+// gbnfLiteral quotes s as a GBNF string literal
+func gbnfLiteral(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteByte('"')
+	return b.String()
+}
+
+// repetition returns the repetition operator for the optional bounds, using
+// def for a missing minimum
+func repetition(min, max *int, def int) string {
+	lower, upper := def, -1
+	if min != nil {
+		lower = *min
+	}
+	if max != nil {
+		upper = *max
+	}
+	return repetitionOf(lower, upper)
+}
+
+// repetitionOf returns the repetition operator for min to max times, max
+// being unbounded when negative
+func repetitionOf(min, max int) string {
+	switch {
+	case max < 0 && min == 0:
+		return "*"
+	case max < 0 && min == 1:
+		return "+"
+	case max < 0:
+		return fmt.Sprintf("{%d,}", min)
+	case min == max:
+		return fmt.Sprintf("{%d}", min)
+	default:
+		return fmt.Sprintf("{%d,%d}", min, max)
+	}
+}
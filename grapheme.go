@@ -0,0 +1,197 @@
+package gsv
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// graphemeClass is the grapheme cluster break property of a rune, as far as
+// it's needed to tell where user-perceived characters begin and end
+type graphemeClass int
+
+const (
+	graphemeOther graphemeClass = iota
+	graphemeCR
+	graphemeLF
+	graphemeControl
+	graphemeExtend
+	graphemeZWJ
+	graphemeSpacingMark
+	graphemeRegionalIndicator
+	graphemeHangulL
+	graphemeHangulV
+	graphemeHangulT
+	graphemeHangulLV
+	graphemeHangulLVT
+	graphemePictographic
+)
+
+// graphemeCount returns the number of grapheme clusters in s. It follows the
+// extended grapheme cluster rules of Unicode UAX #29 for line breaks, control
+// characters, combining marks, Hangul syllables, flags and emoji sequences,
+// which covers what LLMs and users type in practice without pulling in the
+// full Unicode property tables.
+func graphemeCount(s string) int {
+	count := 0
+
+	prev := graphemeControl
+	inPictographic := false // an emoji optionally followed by Extend runes
+	regionalIndicators := 0 // regional indicators in a row, for flag pairs
+
+	for i, r := range s {
+		class := graphemeClassOf(r)
+
+		if i == 0 || graphemeBreak(prev, class, inPictographic, regionalIndicators) {
+			count++
+		}
+
+		switch class {
+		case graphemePictographic:
+			inPictographic = true
+		case graphemeExtend:
+			// Extend runes keep the emoji sequence going
+		case graphemeZWJ:
+			inPictographic = inPictographic && prev != graphemeZWJ
+		default:
+			inPictographic = false
+		}
+
+		if class == graphemeRegionalIndicator {
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+
+		prev = class
+	}
+
+	return count
+}
+
+// graphemeBreak reports whether there's a grapheme cluster boundary between a
+// rune of class prev and a rune of class next
+func graphemeBreak(prev, next graphemeClass, inPictographic bool, regionalIndicators int) bool {
+	switch {
+	// GB3: CR × LF
+	case prev == graphemeCR && next == graphemeLF:
+		return false
+
+	// GB4, GB5: break after and before controls
+	case prev == graphemeCR || prev == graphemeLF || prev == graphemeControl:
+		return true
+	case next == graphemeCR || next == graphemeLF || next == graphemeControl:
+		return true
+
+	// GB6: L × (L | V | LV | LVT)
+	case prev == graphemeHangulL &&
+		(next == graphemeHangulL || next == graphemeHangulV || next == graphemeHangulLV || next == graphemeHangulLVT):
+		return false
+
+	// GB7: (LV | V) × (V | T)
+	case (prev == graphemeHangulLV || prev == graphemeHangulV) &&
+		(next == graphemeHangulV || next == graphemeHangulT):
+		return false
+
+	// GB8: (LVT | T) × T
+	case (prev == graphemeHangulLVT || prev == graphemeHangulT) && next == graphemeHangulT:
+		return false
+
+	// GB9, GB9a: × (Extend | ZWJ | SpacingMark)
+	case next == graphemeExtend || next == graphemeZWJ || next == graphemeSpacingMark:
+		return false
+
+	// GB11: ExtPict Extend* ZWJ × ExtPict
+	case prev == graphemeZWJ && next == graphemePictographic && inPictographic:
+		return false
+
+	// GB12, GB13: flags are pairs of regional indicators
+	case prev == graphemeRegionalIndicator && next == graphemeRegionalIndicator:
+		return regionalIndicators%2 == 0
+	}
+
+	// GB999: break everywhere else
+	return true
+}
+
+// graphemeClassOf returns the grapheme cluster break class of r
+func graphemeClassOf(r rune) graphemeClass {
+	switch {
+	case r == '\r':
+		return graphemeCR
+	case r == '\n':
+		return graphemeLF
+	case r == '\u200d':
+		return graphemeZWJ
+	case r == '\u200c':
+		return graphemeExtend
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return graphemeRegionalIndicator
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		// Emoji skin tone modifiers
+		return graphemeExtend
+	case r >= 0xE0020 && r <= 0xE007F:
+		// Tags used by subdivision flags
+		return graphemeExtend
+	case unicode.In(r, unicode.Cc, unicode.Zl, unicode.Zp, unicode.Cf) || r == utf8.RuneError:
+		return graphemeControl
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return graphemeExtend
+	case unicode.Is(unicode.Mc, r):
+		return graphemeSpacingMark
+	}
+
+	if class, ok := hangulClassOf(r); ok {
+		return class
+	}
+
+	if isPictographic(r) {
+		return graphemePictographic
+	}
+
+	return graphemeOther
+}
+
+// hangulClassOf returns the class of the Hangul jamo and syllables
+func hangulClassOf(r rune) (graphemeClass, bool) {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return graphemeHangulL, true
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return graphemeHangulV, true
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return graphemeHangulT, true
+	case r >= 0xAC00 && r <= 0xD7A3:
+		// Every 28th precomposed syllable has no trailing consonant
+		if (r-0xAC00)%28 == 0 {
+			return graphemeHangulLV, true
+		}
+		return graphemeHangulLVT, true
+	}
+
+	return graphemeOther, false
+}
+
+// isPictographic reports whether r is in one of the blocks holding the
+// Extended_Pictographic runes, i.e. emoji and the symbols used as emoji
+func isPictographic(r rune) bool {
+	switch {
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139:
+		return true
+	case r >= 0x2194 && r <= 0x21AA:
+		return true
+	case r >= 0x231A && r <= 0x23FF:
+		return true
+	case r >= 0x25AA && r <= 0x25FE:
+		return true
+	case r >= 0x2600 && r <= 0x27BF:
+		return true
+	case r >= 0x2934 && r <= 0x2935, r >= 0x2B05 && r <= 0x2B55:
+		return true
+	case r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299:
+		return true
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	}
+
+	return false
+}
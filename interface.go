@@ -22,6 +22,12 @@ type Schema interface {
 	// IsOptional denotes that a given schema is optional.
 	IsOptional() bool
 
+	// IsNullable denotes that JSON null is a valid value of the schema.
+	IsNullable() bool
+
+	// State denotes whether the value of the schema is absent, null or present.
+	State() ValueState
+
 	// TODO IsNotOptional
 	// CompileJSONSchema for the JSON schema compiling
 	CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error
@@ -35,4 +41,11 @@ type Schema interface {
 
 	setValue(interface{}) error
 	getValue() (interface{}, bool)
+
+	// decode stores the JSON value in data without running any validators. The
+	// ParseOptions denote how values of the wrong type are handled.
+	decode([]byte, *ParseOptions) error
+
+	// validate runs the registered validators honoring the given ParseOptions
+	validate(*ParseOptions) *ValidationResult
 }
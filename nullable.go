@@ -0,0 +1,72 @@
+package gsv
+
+import "github.com/agent-api/gsv/pkg/jsonschema"
+
+// NullSchemaType is the JSON schema type of null
+const NullSchemaType string = "null"
+
+// NullValueError is reported for a JSON null given to a schema that isn't
+// nullable, even an optional one
+const NullValueError ValidationErrorType = "null_value"
+
+// ValueState denotes whether the value of a schema is absent, null or present.
+// A JSON null is Null for every schema but only valid for nullable ones.
+type ValueState int
+
+const (
+	// Absent denotes the schema has no value, e.g. its key was missing
+	Absent ValueState = iota
+
+	// Null denotes the value is a JSON null
+	Null
+
+	// Present denotes the schema holds a value
+	Present
+)
+
+// String implements fmt.Stringer
+func (s ValueState) String() string {
+	switch s {
+	case Null:
+		return "null"
+	case Present:
+		return "present"
+	default:
+		return "absent"
+	}
+}
+
+// valueState returns the state of a schema from whether it's null and has a
+// value
+func valueState(isNull, hasValue bool) ValueState {
+	switch {
+	case isNull:
+		return Null
+	case hasValue:
+		return Present
+	default:
+		return Absent
+	}
+}
+
+// newNullError returns the error for a JSON null given to a schema that isn't
+// nullable
+func newNullError() *ValidationError {
+	return &ValidationError{
+		Type:    NullValueError,
+		Message: "value cannot be null",
+	}
+}
+
+// jsonSchemaTypes returns the "type" keyword of a schema of type typ, which
+// also allows null for nullable schemas. No type is returned for schemas
+// without a single type.
+func jsonSchemaTypes(typ string, nullable bool) jsonschema.TypeList {
+	if typ == "" {
+		return nil
+	}
+	if nullable {
+		return jsonschema.Types(typ, NullSchemaType)
+	}
+	return jsonschema.Types(typ)
+}
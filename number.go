@@ -4,18 +4,36 @@ import (
 	"cmp"
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 
 	"github.com/agent-api/gsv/pkg/jsonschema"
 )
 
 const (
-	MinNumberError         ValidationErrorType = "min_number"
-	MaxNumberError         ValidationErrorType = "max_number"
-	RequiredNumberError    ValidationErrorType = "required_number"
-	InvalidNumberTypeError ValidationErrorType = "invalid_number_type"
+	NumberSchemaType  string = "number"
+	IntegerSchemaType string = "integer"
 )
 
-type NumberValidatorFunc[T cmp.Ordered] func(T)
+const (
+	MinNumberError          ValidationErrorType = "min_number"
+	MaxNumberError          ValidationErrorType = "max_number"
+	ExclusiveMinNumberError ValidationErrorType = "exclusive_min_number"
+	ExclusiveMaxNumberError ValidationErrorType = "exclusive_max_number"
+	MultipleOfNumberError   ValidationErrorType = "multiple_of_number"
+	IntegerNumberError      ValidationErrorType = "integer_number"
+	UnsafeNumberError       ValidationErrorType = "unsafe_number"
+	RequiredNumberError     ValidationErrorType = "required_number"
+	InvalidNumberTypeError  ValidationErrorType = "invalid_number_type"
+)
+
+// MaxSafeInteger is the largest integer a JSON number can represent exactly
+// when it's decoded as a double precision float: 2^53 - 1
+const MaxSafeInteger = 1<<53 - 1
+
+// numberValidatorFunc is a validation function that expects a number and
+// returns a ValidationError if the number is invalid
+type numberValidatorFunc[T cmp.Ordered] func(T) *ValidationError
 
 // NumberSchema implements the Schema interface. It represents a generic "number"
 // with types implemented in int.go, uint.go, float.go, and rune.go.
@@ -24,9 +42,43 @@ type NumberSchema[T cmp.Ordered] struct {
 	max   *T
 	value *T
 
+	// gt and lt are the exclusive bounds of the number
+	gt *T
+	lt *T
+
+	// multipleOf denotes the number must be a multiple of this value
+	multipleOf *T
+
+	// isInt denotes the number must be a whole number
+	isInt bool
+
+	// isSafe denotes the number must be within +/- MaxSafeInteger
+	isSafe bool
+
 	description *string
 
-	validators []NumberValidatorFunc[T]
+	validators []numberValidatorFunc[T]
+
+	// preprocessors and transforms normalize the number while it's decoded
+	preprocessors []preprocessFunc
+	transforms    []func(T) (T, error)
+
+	// transformErr is the error of the last failed transform
+	transformErr error
+
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[T]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
+
+	// coerce denotes numeric strings are decoded as numbers and coercion
+	// records the last one that was
+	coerce   bool
+	coercion *coercion
+
 	isOptional bool
 	result     *ValidationResult
 }
@@ -34,7 +86,7 @@ type NumberSchema[T cmp.Ordered] struct {
 // Number creates a new number validator for a specific type
 func Number[T cmp.Ordered]() *NumberSchema[T] {
 	return &NumberSchema[T]{
-		validators: make([]NumberValidatorFunc[T], 0),
+		validators: make([]numberValidatorFunc[T], 0),
 		isOptional: false,
 	}
 }
@@ -47,15 +99,16 @@ func (n *NumberSchema[T]) Min(min T, opts ...ValidationOptions) *NumberSchema[T]
 		validationMessage = opts[0].Message
 	}
 
-	n.validators = append(n.validators, func(v T) {
+	n.validators = append(n.validators, func(v T) *ValidationError {
 		if v < min {
-			n.result.AddError(&ValidationError{
+			return &ValidationError{
 				Type:     MinNumberError,
 				Message:  validationMessage,
 				Expected: min,
 				Actual:   v,
-			})
+			}
 		}
+		return nil
 	})
 
 	return n
@@ -69,20 +122,231 @@ func (n *NumberSchema[T]) Max(max T, opts ...ValidationOptions) *NumberSchema[T]
 		validationMessage = opts[0].Message
 	}
 
-	n.validators = append(n.validators, func(v T) {
+	n.validators = append(n.validators, func(v T) *ValidationError {
 		if v > max {
-			n.result.AddError(&ValidationError{
+			return &ValidationError{
 				Type:     MaxNumberError,
 				Message:  validationMessage,
 				Expected: max,
 				Actual:   v,
-			})
+			}
+		}
+		return nil
+	})
+
+	return n
+}
+
+// Gt adds an exclusive minimum validation: the number must be greater than gt
+func (n *NumberSchema[T]) Gt(gt T, opts ...ValidationOptions) *NumberSchema[T] {
+	n.gt = &gt
+
+	validationMessage := fmt.Sprintf("must be greater than %v", gt)
+	if len(opts) > 0 && opts[0].Message != "" {
+		validationMessage = opts[0].Message
+	}
+
+	n.validators = append(n.validators, func(v T) *ValidationError {
+		if v <= gt {
+			return &ValidationError{
+				Type:     ExclusiveMinNumberError,
+				Message:  validationMessage,
+				Expected: gt,
+				Actual:   v,
+			}
+		}
+		return nil
+	})
+
+	return n
+}
+
+// Lt adds an exclusive maximum validation: the number must be less than lt
+func (n *NumberSchema[T]) Lt(lt T, opts ...ValidationOptions) *NumberSchema[T] {
+	n.lt = &lt
+
+	validationMessage := fmt.Sprintf("must be less than %v", lt)
+	if len(opts) > 0 && opts[0].Message != "" {
+		validationMessage = opts[0].Message
+	}
+
+	n.validators = append(n.validators, func(v T) *ValidationError {
+		if v >= lt {
+			return &ValidationError{
+				Type:     ExclusiveMaxNumberError,
+				Message:  validationMessage,
+				Expected: lt,
+				Actual:   v,
+			}
+		}
+		return nil
+	})
+
+	return n
+}
+
+// Positive requires the number to be greater than 0
+func (n *NumberSchema[T]) Positive(opts ...ValidationOptions) *NumberSchema[T] {
+	return n.Gt(*new(T), opts...)
+}
+
+// NonNegative requires the number to be at least 0
+func (n *NumberSchema[T]) NonNegative(opts ...ValidationOptions) *NumberSchema[T] {
+	return n.Min(*new(T), opts...)
+}
+
+// Negative requires the number to be less than 0
+func (n *NumberSchema[T]) Negative(opts ...ValidationOptions) *NumberSchema[T] {
+	return n.Lt(*new(T), opts...)
+}
+
+// NonPositive requires the number to be at most 0
+func (n *NumberSchema[T]) NonPositive(opts ...ValidationOptions) *NumberSchema[T] {
+	return n.Max(*new(T), opts...)
+}
+
+// MultipleOf requires the number to be a multiple of the given value
+func (n *NumberSchema[T]) MultipleOf(multipleOf T, opts ...ValidationOptions) *NumberSchema[T] {
+	mustBeNumeric[T]("MultipleOf")
+	if multipleOf <= *new(T) {
+		panic("multipleOf must be greater than 0")
+	}
+	n.multipleOf = &multipleOf
+
+	validationMessage := fmt.Sprintf("must be a multiple of %v", multipleOf)
+	if len(opts) > 0 && opts[0].Message != "" {
+		validationMessage = opts[0].Message
+	}
+
+	n.validators = append(n.validators, func(v T) *ValidationError {
+		if !isMultipleOf(v, multipleOf) {
+			return &ValidationError{
+				Type:     MultipleOfNumberError,
+				Message:  validationMessage,
+				Expected: multipleOf,
+				Actual:   v,
+			}
+		}
+		return nil
+	})
+
+	return n
+}
+
+// Refine adds a custom validation. The check returns false for invalid
+// numbers, which reports a CustomError with the message from the options.
+func (n *NumberSchema[T]) Refine(check func(T) bool, opts ...ValidationOptions) *NumberSchema[T] {
+	n.validators = append(n.validators, numberValidatorFunc[T](newRefinement(check, opts)))
+	return n
+}
+
+// Preprocess adds a function that rewrites the decoded JSON value before it's
+// decoded as a number, e.g. to parse numeric strings
+func (n *NumberSchema[T]) Preprocess(fn func(interface{}) interface{}) *NumberSchema[T] {
+	n.preprocessors = append(n.preprocessors, fn)
+	return n
+}
+
+// Transform adds a function that normalizes the number while it's decoded,
+// e.g. rounding, so the validators see the normalized number. An error is
+// reported as a TransformError.
+func (n *NumberSchema[T]) Transform(fn func(T) (T, error)) *NumberSchema[T] {
+	n.transforms = append(n.transforms, fn)
+	return n
+}
+
+// Coerce decodes numeric strings like "42" as numbers. Every coerced value is
+// reported as a CoercionWarning when validating.
+func (n *NumberSchema[T]) Coerce() *NumberSchema[T] {
+	n.coerce = true
+	return n
+}
+
+// Int requires the number to be a whole number. This is mostly useful for
+// float schemas since integer schemas can only hold whole numbers.
+func (n *NumberSchema[T]) Int(opts ...ValidationOptions) *NumberSchema[T] {
+	mustBeNumeric[T]("Int")
+	n.isInt = true
+
+	validationMessage := "must be an integer"
+	if len(opts) > 0 && opts[0].Message != "" {
+		validationMessage = opts[0].Message
+	}
+
+	n.validators = append(n.validators, func(v T) *ValidationError {
+		if f := toFloat64(v); f != math.Trunc(f) {
+			return &ValidationError{
+				Type:     IntegerNumberError,
+				Message:  validationMessage,
+				Expected: IntegerSchemaType,
+				Actual:   v,
+			}
+		}
+		return nil
+	})
+
+	return n
+}
+
+// Safe requires the number to be within -MaxSafeInteger and MaxSafeInteger,
+// the range of integers every JSON parser can represent exactly
+func (n *NumberSchema[T]) Safe(opts ...ValidationOptions) *NumberSchema[T] {
+	mustBeNumeric[T]("Safe")
+	n.isSafe = true
+
+	validationMessage := fmt.Sprintf("must be between %d and %d", -MaxSafeInteger, MaxSafeInteger)
+	if len(opts) > 0 && opts[0].Message != "" {
+		validationMessage = opts[0].Message
+	}
+
+	n.validators = append(n.validators, func(v T) *ValidationError {
+		if f := toFloat64(v); f < -MaxSafeInteger || f > MaxSafeInteger {
+			return &ValidationError{
+				Type:     UnsafeNumberError,
+				Message:  validationMessage,
+				Expected: MaxSafeInteger,
+				Actual:   v,
+			}
 		}
+		return nil
 	})
 
 	return n
 }
 
+// Nullable marks JSON null as a valid value of the number. Unlike Optional,
+// the key must still be present unless the number is optional as well.
+func (n *NumberSchema[T]) Nullable() *NumberSchema[T] {
+	n.isNullable = true
+	return n
+}
+
+// IsNullable implements Schema.IsNullable
+func (n *NumberSchema[T]) IsNullable() bool {
+	return n.isNullable
+}
+
+// State implements Schema.State
+func (n *NumberSchema[T]) State() ValueState {
+	_, ok := n.getValue()
+	return valueState(n.isNull, ok)
+}
+
+// Default marks the number field as optional and sets the value it takes when
+// it's missing
+func (n *NumberSchema[T]) Default(v T) *NumberSchema[T] {
+	n.defaultValue = newDefault(v)
+	n.isOptional = true
+	return n
+}
+
+// DefaultFunc is Default with a value built by fn for every missing number
+func (n *NumberSchema[T]) DefaultFunc(fn func() T) *NumberSchema[T] {
+	n.defaultValue = newDefaultFunc(fn)
+	n.isOptional = true
+	return n
+}
+
 // Optional marks the int field as optional
 func (n *NumberSchema[T]) Optional() *NumberSchema[T] {
 	n.isOptional = true
@@ -99,23 +363,35 @@ func (n *NumberSchema[T]) Description(val string) *NumberSchema[T] {
 }
 
 func (n *NumberSchema[T]) Set(v T) *NumberSchema[T] {
+	n.isNull = false
 	n.value = &v
+	n.transformErr = nil
+	n.coercion = nil
 	return n
 }
 
 func (n *NumberSchema[T]) setValue(val interface{}) error {
+	if val == nil && n.isNullable {
+		n.value = nil
+		n.isNull = true
+		return nil
+	}
+	n.isNull = false
+
 	num, ok := val.(T)
 	if !ok {
 		return fmt.Errorf("expected %T value, got %T", *new(T), val)
 	}
 	n.value = &num
+	n.transformErr = nil
+	n.coercion = nil
 	return nil
 }
 
 // NumberSchema
 func (n *NumberSchema[T]) Value() (T, bool) {
 	val, ok := n.getValue()
-	if !ok {
+	if !ok || val == nil {
 		var zero T
 		return zero, false
 	}
@@ -127,6 +403,10 @@ func (n *NumberSchema[T]) Value() (T, bool) {
 }
 
 func (n *NumberSchema[T]) getValue() (interface{}, bool) {
+	if n.isNull {
+		return nil, true
+	}
+
 	if n.value == nil {
 		return nil, false
 	}
@@ -135,24 +415,57 @@ func (n *NumberSchema[T]) getValue() (interface{}, bool) {
 
 // Validate performs the validation
 func (n *NumberSchema[T]) Validate() *ValidationResult {
+	return n.validate(&ParseOptions{})
+}
+
+func (n *NumberSchema[T]) validate(opts *ParseOptions) *ValidationResult {
 	n.result = &ValidationResult{}
 
+	if n.isNull {
+		if !n.isNullable {
+			n.result.AddError(newNullError())
+		}
+		return n.result
+	}
+
 	val, ok := n.Value()
-	if !ok && n.isOptional {
-		n.result.AddError(&ValidationError{
-			Type:    RequiredNumberError,
-			Message: "value has not been set",
-		})
+	if !ok {
+		if !n.isOptional {
+			n.result.AddError(&ValidationError{
+				Type:    RequiredNumberError,
+				Message: "value has not been set",
+			})
+		}
+		return n.result
+	}
+
+	if n.coercion != nil {
+		n.result.AddWarning(n.coercion.warning())
+	}
+
+	if n.transformErr != nil {
+		n.result.AddError(newTransformError(n.transformErr, val))
+		return n.result
 	}
 
 	for _, validator := range n.validators {
-		validator(val)
+		if err := validator(val); err != nil {
+			n.result.AddError(err)
+
+			if opts.StopOnFirst {
+				break
+			}
+		}
 	}
 
 	return n.result
 }
 
 func (n *NumberSchema[T]) MarshalJSON() ([]byte, error) {
+	if n.isNull {
+		return json.Marshal(nil)
+	}
+
 	if n.value == nil {
 		if n.isOptional {
 			return json.Marshal(nil)
@@ -163,36 +476,77 @@ func (n *NumberSchema[T]) MarshalJSON() ([]byte, error) {
 }
 
 func (n *NumberSchema[T]) UnmarshalJSON(data []byte) error {
-	if string(data) == "null" {
-		if !n.isOptional {
-			return fmt.Errorf("validation failed: field is required")
-		}
+	if err := n.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	if result := n.Validate(); result.HasErrors() {
+		return result.Error()
+	}
+
+	return nil
+}
+
+func (n *NumberSchema[T]) decode(data []byte, opts *ParseOptions) error {
+	data, err := preprocess(data, n.preprocessors)
+	if err != nil {
+		return fmt.Errorf("invalid numeric value: %w", err)
+	}
+
+	n.transformErr = nil
+	n.coercion = nil
+
+	n.isNull = string(data) == "null"
+
+	if len(data) == 0 || string(data) == "null" {
 		n.value = nil
+		applyDefault(n, n.defaultValue)
 		return nil
 	}
 
+	if n.coerce || opts.Coerce {
+		if number, ok := coerceNumber(data); ok {
+			n.coercion = newCoercion(data, n.jsonSchemaType())
+			data = number
+		}
+	}
+
 	var v T
 	if err := json.Unmarshal(data, &v); err != nil {
 		return fmt.Errorf("invalid numeric value: %w", err)
 	}
 
-	n.value = &v
-
-	if result := n.Validate(); result.HasErrors() {
-		return result.Error()
+	// Normalize the value, keeping the original one if a transform fails
+	if transformed, err := transformValue(v, n.transforms); err != nil {
+		n.transformErr = err
+	} else {
+		v = transformed
 	}
 
+	n.value = &v
+
 	return nil
 }
 
 func (n *NumberSchema[T]) Clone() Schema {
 	// Create new instance
 	clone := &NumberSchema[T]{
+		isInt:      n.isInt,
+		isSafe:     n.isSafe,
 		isOptional: n.isOptional,
-		validators: make([]NumberValidatorFunc[T], len(n.validators)),
+		validators: make([]numberValidatorFunc[T], len(n.validators)),
+
+		preprocessors: make([]preprocessFunc, len(n.preprocessors)),
+		transforms:    make([]func(T) (T, error), len(n.transforms)),
+		transformErr:  n.transformErr,
+
+		coerce:   n.coerce,
+		coercion: n.coercion,
 	}
 	// Deep copy validators slice
 	copy(clone.validators, n.validators)
+	copy(clone.preprocessors, n.preprocessors)
+	copy(clone.transforms, n.transforms)
 
 	// Deep copy pointer fields
 	if n.min != nil {
@@ -203,6 +557,18 @@ func (n *NumberSchema[T]) Clone() Schema {
 		max := *n.max
 		clone.max = &max
 	}
+	if n.gt != nil {
+		gt := *n.gt
+		clone.gt = &gt
+	}
+	if n.lt != nil {
+		lt := *n.lt
+		clone.lt = &lt
+	}
+	if n.multipleOf != nil {
+		multipleOf := *n.multipleOf
+		clone.multipleOf = &multipleOf
+	}
 	if n.value != nil {
 		val := *n.value
 		clone.value = &val
@@ -215,6 +581,10 @@ func (n *NumberSchema[T]) Clone() Schema {
 	// Initialize new validation result
 	clone.result = &ValidationResult{}
 
+	clone.defaultValue = n.defaultValue
+	clone.isNullable = n.isNullable
+	clone.isNull = n.isNull
+
 	return clone
 }
 
@@ -224,7 +594,7 @@ func (n *NumberSchema[T]) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonT
 	}
 
 	propertySchema := &jsonschema.JSONSchema{
-		Type: "number",
+		Type: jsonSchemaTypes(n.jsonSchemaType(), n.isNullable),
 	}
 
 	// Add description if present
@@ -232,6 +602,35 @@ func (n *NumberSchema[T]) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonT
 		propertySchema.Description = *n.description
 	}
 
+	propertySchema.Default = n.defaultValue.jsonSchemaDefault()
+
+	// Add the bounds if present
+	if n.min != nil {
+		propertySchema.Minimum = float64Ptr(*n.min)
+	}
+	if n.max != nil {
+		propertySchema.Maximum = float64Ptr(*n.max)
+	}
+	if n.gt != nil {
+		propertySchema.ExclusiveMinimum = float64Ptr(*n.gt)
+	}
+	if n.lt != nil {
+		propertySchema.ExclusiveMaximum = float64Ptr(*n.lt)
+	}
+	if n.multipleOf != nil {
+		propertySchema.MultipleOf = float64Ptr(*n.multipleOf)
+	}
+
+	// Tighten the bounds to the safe integer range
+	if n.isSafe {
+		if propertySchema.Minimum == nil || *propertySchema.Minimum < -MaxSafeInteger {
+			propertySchema.Minimum = float64Ptr(-MaxSafeInteger)
+		}
+		if propertySchema.Maximum == nil || *propertySchema.Maximum > MaxSafeInteger {
+			propertySchema.Maximum = float64Ptr(MaxSafeInteger)
+		}
+	}
+
 	// Add to required fields if not optional
 	if !n.IsOptional() {
 		schema.Required = append(schema.Required, jsonTag)
@@ -240,3 +639,70 @@ func (n *NumberSchema[T]) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonT
 	schema.Properties[jsonTag] = propertySchema
 	return nil
 }
+
+// jsonSchemaType returns the JSON schema type of the number: "integer" for
+// Go's integer types and floats limited to whole numbers, "number" otherwise
+func (n *NumberSchema[T]) jsonSchemaType() string {
+	if n.isInt || isIntegerType[T]() {
+		return IntegerSchemaType
+	}
+
+	return NumberSchemaType
+}
+
+// isIntegerType reports whether T is one of Go's integer types
+func isIntegerType[T cmp.Ordered]() bool {
+	switch reflect.TypeOf(*new(T)).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// mustBeNumeric panics if T isn't one of Go's integer or float types. The
+// NumberSchema constraint is cmp.Ordered so the validators only working on
+// numbers check T when they're added.
+func mustBeNumeric[T cmp.Ordered](validator string) {
+	if reflect.TypeOf(*new(T)).Kind() == reflect.String {
+		panic(fmt.Sprintf("NumberSchema: %s requires a numeric type, got %T", validator, *new(T)))
+	}
+}
+
+// toFloat64 converts a number to a float64. Values that aren't numbers
+// convert to 0.
+func toFloat64[T cmp.Ordered](v T) float64 {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int())
+	case rv.CanUint():
+		return float64(rv.Uint())
+	case rv.CanFloat():
+		return rv.Float()
+	default:
+		return 0
+	}
+}
+
+// isMultipleOf reports whether v is a multiple of m. Integers are checked
+// exactly while floats allow for a small rounding error.
+func isMultipleOf[T cmp.Ordered](v, m T) bool {
+	rv, rm := reflect.ValueOf(v), reflect.ValueOf(m)
+	switch {
+	case rv.CanInt():
+		return rv.Int()%rm.Int() == 0
+	case rv.CanUint():
+		return rv.Uint()%rm.Uint() == 0
+	}
+
+	quotient := toFloat64(v) / toFloat64(m)
+	return math.Abs(quotient-math.Round(quotient)) < 1e-9
+}
+
+// float64Ptr converts a number to a float64 pointer for the JSON schema
+func float64Ptr[T cmp.Ordered](v T) *float64 {
+	f := toFloat64(v)
+	return &f
+}
@@ -0,0 +1,645 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+const (
+	ObjectSchemaType                            = "object"
+	RequiredObjectError     ValidationErrorType = "required_object"
+	UnknownObjectKeyError   ValidationErrorType = "unknown_object_key"
+	InvalidObjectValueError ValidationErrorType = "invalid_object_value"
+)
+
+// unknownKeysMode denotes how an ObjectSchema handles keys that aren't part
+// of its shape
+type unknownKeysMode int
+
+const (
+	// stripUnknownKeys silently drops unknown keys
+	stripUnknownKeys unknownKeysMode = iota
+
+	// strictUnknownKeys reports every unknown key as a validation error
+	strictUnknownKeys
+
+	// passthroughUnknownKeys keeps unknown keys as they are
+	passthroughUnknownKeys
+
+	// catchallUnknownKeys keeps unknown keys and validates them against the
+	// catchall schema
+	catchallUnknownKeys
+)
+
+// ObjectSchema implements the Schema interface for JSON objects. Its shape
+// maps each known key to the schema of its value.
+type ObjectSchema struct {
+	schemaType string
+
+	// shape holds the schemas of the known keys
+	shape map[string]Schema
+
+	// keys are the known keys in a stable order
+	keys []string
+
+	// mode denotes what happens with keys that aren't in the shape
+	mode unknownKeysMode
+
+	// catchall validates the unknown keys in catchall mode
+	catchall Schema
+
+	// unknown holds the values of the keys that aren't in the shape. They're
+	// kept in every mode but strip so strict mode can report them later on.
+	unknown map[string]interface{}
+
+	// catchallEntries hold the catchall schemas the unknown keys were decoded
+	// with, so errors of their transforms aren't lost
+	catchallEntries map[string]Schema
+
+	// preprocessors rewrite the JSON value before it's decoded
+	preprocessors []preprocessFunc
+
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[map[string]interface{}]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
+
+	// isSet denotes that the object has a value
+	isSet bool
+
+	// refinements and superRefinements are the custom validations of the
+	// whole object
+	refinements      []refinement[map[string]interface{}]
+	superRefinements []superRefinement[map[string]interface{}]
+
+	description *string
+
+	// isOptional denotes if the object in the schema is optional
+	isOptional bool
+
+	// the result of the last validation
+	result *ValidationResult
+}
+
+// Object creates a new object schema with the given shape. Unknown keys are
+// stripped unless Strict, Passthrough or Catchall is used.
+func Object(shape map[string]Schema) *ObjectSchema {
+	o := &ObjectSchema{
+		schemaType: ObjectSchemaType,
+		shape:      make(map[string]Schema, len(shape)),
+		keys:       make([]string, 0, len(shape)),
+		result:     &ValidationResult{},
+	}
+
+	for key, schema := range shape {
+		if schema == nil {
+			panic(fmt.Sprintf("schema for key %q cannot be nil", key))
+		}
+
+		o.shape[key] = schema
+		o.keys = append(o.keys, key)
+	}
+
+	sort.Strings(o.keys)
+
+	return o
+}
+
+// Strict rejects keys that aren't part of the shape
+func (o *ObjectSchema) Strict() *ObjectSchema {
+	o.mode = strictUnknownKeys
+	o.catchall = nil
+	return o
+}
+
+// Strip silently drops keys that aren't part of the shape. This is the default.
+func (o *ObjectSchema) Strip() *ObjectSchema {
+	o.mode = stripUnknownKeys
+	o.catchall = nil
+	return o
+}
+
+// Passthrough keeps keys that aren't part of the shape without validating them
+func (o *ObjectSchema) Passthrough() *ObjectSchema {
+	o.mode = passthroughUnknownKeys
+	o.catchall = nil
+	return o
+}
+
+// Catchall keeps keys that aren't part of the shape and validates their values
+// against the given schema
+func (o *ObjectSchema) Catchall(schema Schema) *ObjectSchema {
+	if schema == nil {
+		panic("catchall schema cannot be nil")
+	}
+
+	o.mode = catchallUnknownKeys
+	o.catchall = schema
+	return o
+}
+
+// Preprocess adds a function that rewrites the decoded JSON value before it's
+// decoded as an object
+func (o *ObjectSchema) Preprocess(fn func(interface{}) interface{}) *ObjectSchema {
+	o.preprocessors = append(o.preprocessors, fn)
+	return o
+}
+
+// Refine adds a custom validation of the whole object. It only runs once
+// every key is valid.
+func (o *ObjectSchema) Refine(check func(map[string]interface{}) bool, opts ...ValidationOptions) *ObjectSchema {
+	o.refinements = append(o.refinements, newRefinement(check, opts))
+	return o
+}
+
+// SuperRefine adds a custom validation of the whole object that can report
+// any number of errors at specific paths through the RefinementContext, e.g.
+// an error at "confirm" when it doesn't match "password". It only runs once
+// every key is valid.
+func (o *ObjectSchema) SuperRefine(refine func(ctx *RefinementContext, value map[string]interface{})) *ObjectSchema {
+	if refine == nil {
+		panic("super refine function cannot be nil")
+	}
+
+	o.superRefinements = append(o.superRefinements, refine)
+	return o
+}
+
+// Description sets the description of the object
+func (o *ObjectSchema) Description(val string) *ObjectSchema {
+	o.description = &val
+	return o
+}
+
+// Nullable marks JSON null as a valid value of the object. Unlike Optional,
+// the key must still be present unless the object is optional as well.
+func (o *ObjectSchema) Nullable() *ObjectSchema {
+	o.isNullable = true
+	return o
+}
+
+// IsNullable implements Schema.IsNullable
+func (o *ObjectSchema) IsNullable() bool {
+	return o.isNullable
+}
+
+// State implements Schema.State
+func (o *ObjectSchema) State() ValueState {
+	_, ok := o.getValue()
+	return valueState(o.isNull, ok)
+}
+
+// Default marks the object field as optional and sets the value it takes when
+// it's missing. It panics if the object doesn't accept v.
+func (o *ObjectSchema) Default(v map[string]interface{}) *ObjectSchema {
+	if err := o.Clone().setValue(v); err != nil {
+		panic(fmt.Sprintf("invalid default: %v", err))
+	}
+
+	o.defaultValue = newDefault(v)
+	o.isOptional = true
+	return o
+}
+
+// DefaultFunc is Default with a value built by fn for every missing object
+func (o *ObjectSchema) DefaultFunc(fn func() map[string]interface{}) *ObjectSchema {
+	o.defaultValue = newDefaultFunc(fn)
+	o.isOptional = true
+	return o
+}
+
+// Optional marks the object field as optional
+func (o *ObjectSchema) Optional() *ObjectSchema {
+	o.isOptional = true
+	return o
+}
+
+// IsOptional implements Schema.IsOptional
+func (o *ObjectSchema) IsOptional() bool {
+	return o.isOptional
+}
+
+// Shape returns the schema of every known key. The schemas hold the decoded
+// values after parsing.
+func (o *ObjectSchema) Shape() map[string]Schema {
+	return o.shape
+}
+
+// Field returns the schema of a known key or nil if the key isn't in the shape
+func (o *ObjectSchema) Field(key string) Schema {
+	return o.shape[key]
+}
+
+// Set provides a way to set the object's values. Each value must be of the
+// type its schema expects.
+func (o *ObjectSchema) Set(values map[string]interface{}) *ObjectSchema {
+	o.isNull = false
+	o.result = &ValidationResult{}
+
+	if err := o.setValue(values); err != nil {
+		o.result.AddError(&ValidationError{
+			Type:    InvalidObjectValueError,
+			Message: err.Error(),
+		})
+	}
+
+	return o
+}
+
+func (o *ObjectSchema) setValue(val interface{}) error {
+	if val == nil && o.isNullable {
+		o.isSet = false
+		o.resetUnknown()
+		o.isNull = true
+		return nil
+	}
+	o.isNull = false
+
+	values, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected object value, got %T", val)
+	}
+
+	o.resetUnknown()
+
+	for key, v := range values {
+		if schema, ok := o.shape[key]; ok {
+			if err := schema.setValue(v); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			continue
+		}
+
+		if o.mode != stripUnknownKeys {
+			o.unknown[key] = v
+		}
+	}
+
+	o.isSet = true
+
+	return nil
+}
+
+func (o *ObjectSchema) getValue() (interface{}, bool) {
+	if o.isNull {
+		return nil, true
+	}
+
+	if !o.isSet {
+		return nil, false
+	}
+
+	values := make(map[string]interface{}, len(o.keys)+len(o.unknown))
+	for _, key := range o.keys {
+		if v, ok := o.shape[key].getValue(); ok {
+			values[key] = v
+		}
+	}
+
+	for key, v := range o.unknown {
+		values[key] = v
+	}
+
+	return values, true
+}
+
+// Value returns the object's values keyed by their JSON key. Keys without a
+// value are left out. This method returns (nil, false) if the object hasn't
+// been set.
+func (o *ObjectSchema) Value() (map[string]interface{}, bool) {
+	val, ok := o.getValue()
+	if !ok || val == nil {
+		return nil, false
+	}
+	objVal, ok := val.(map[string]interface{})
+	if !ok {
+		panic(fmt.Sprintf("ObjectSchema: invalid internal value type %T, expected map[string]interface{}", val))
+	}
+	return objVal, true
+}
+
+// resetUnknown clears the unknown keys stored by the last decode or set
+func (o *ObjectSchema) resetUnknown() {
+	o.unknown = make(map[string]interface{})
+	o.catchallEntries = nil
+}
+
+// Validate performs the validation
+func (o *ObjectSchema) Validate() *ValidationResult {
+	return o.validate(&ParseOptions{})
+}
+
+func (o *ObjectSchema) validate(opts *ParseOptions) *ValidationResult {
+	o.result = &ValidationResult{}
+
+	if o.isNull {
+		if !o.isNullable {
+			o.result.AddError(newNullError())
+		}
+		return o.result
+	}
+
+	if !o.isSet {
+		if !o.isOptional {
+			o.result.AddError(&ValidationError{
+				Type:    RequiredObjectError,
+				Message: "object is required",
+			})
+		}
+		return o.result
+	}
+
+	for _, key := range o.keys {
+		schema := o.shape[key]
+
+		if opts.SkipMissing {
+			if _, ok := schema.getValue(); !ok {
+				continue
+			}
+		}
+
+		o.addErrors(key, schema.validate(opts))
+		if opts.StopOnFirst && o.result.HasErrors() {
+			return o.result
+		}
+	}
+
+	for _, key := range sortedKeys(o.unknown) {
+		switch o.mode {
+		case strictUnknownKeys:
+			o.result.AddError(newUnknownKeyError(key, o.keys, nil))
+
+		case catchallUnknownKeys:
+			elem, err := o.catchallEntry(key)
+			if err != nil {
+				o.result.AddError(&ValidationError{
+					Type:    InvalidObjectValueError,
+					Field:   jsonPointer([]interface{}{key}),
+					Path:    []interface{}{key},
+					Message: err.Error(),
+				})
+			} else {
+				o.addErrors(key, elem.validate(opts))
+			}
+		}
+
+		if opts.StopOnFirst && o.result.HasErrors() {
+			return o.result
+		}
+	}
+
+	value, _ := o.Value()
+	runRefinements(o.refinements, value, o.result, opts)
+	runSuperRefinements(o.superRefinements, value, o.result, opts)
+
+	return o.result
+}
+
+// catchallEntry returns the catchall schema holding the value of the unknown
+// key, either the one it was decoded with or a new one holding the value
+func (o *ObjectSchema) catchallEntry(key string) (Schema, error) {
+	if elem, ok := o.catchallEntries[key]; ok {
+		return elem, nil
+	}
+
+	elem := o.catchall.Clone()
+	if err := elem.setValue(o.unknown[key]); err != nil {
+		return nil, err
+	}
+
+	return elem, nil
+}
+
+// addErrors adds the errors and warnings of the schema at key to the
+// object's result
+func (o *ObjectSchema) addErrors(key string, result *ValidationResult) {
+	for _, err := range result.Errors {
+		err.prependPath(key)
+		o.result.AddError(err)
+	}
+	o.result.addWarnings(result.Warnings, key)
+}
+
+// MarshalJSON implements json.Marshaler
+func (o *ObjectSchema) MarshalJSON() ([]byte, error) {
+	if o.isNull {
+		return json.Marshal(nil)
+	}
+
+	if !o.isSet {
+		if o.isOptional {
+			return json.Marshal(nil)
+		}
+		return nil, fmt.Errorf("required object has no value")
+	}
+
+	fields := make(map[string]json.RawMessage, len(o.keys)+len(o.unknown))
+	for _, key := range o.keys {
+		schema := o.shape[key]
+		if _, ok := schema.getValue(); !ok && schema.IsOptional() {
+			continue
+		}
+
+		data, err := schema.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		fields[key] = data
+	}
+
+	for key, v := range o.unknown {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		fields[key] = data
+	}
+
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (o *ObjectSchema) UnmarshalJSON(data []byte) error {
+	if err := o.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	return o.Validate().Error()
+}
+
+func (o *ObjectSchema) decode(data []byte, opts *ParseOptions) error {
+	data, err := preprocess(data, o.preprocessors)
+	if err != nil {
+		return fmt.Errorf("invalid object value: %w", err)
+	}
+
+	o.isNull = string(data) == "null"
+
+	if len(data) == 0 || string(data) == "null" {
+		o.isSet = false
+		applyDefault(o, o.defaultValue)
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("invalid object value: %w", err)
+	}
+
+	o.resetUnknown()
+	o.catchallEntries = make(map[string]Schema)
+
+	for _, key := range sortedKeys(fields) {
+		raw := fields[key]
+
+		if schema, ok := o.shape[key]; ok {
+			if err := schema.decode(raw, opts); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			continue
+		}
+
+		switch o.mode {
+		case strictUnknownKeys, passthroughUnknownKeys:
+			var v interface{}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			o.unknown[key] = v
+
+		case catchallUnknownKeys:
+			elem := o.catchall.Clone()
+			if err := elem.decode(raw, opts); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			if v, ok := elem.getValue(); ok {
+				o.unknown[key] = v
+				o.catchallEntries[key] = elem
+			}
+		}
+	}
+
+	// Missing keys are decoded as missing values so they pick up their
+	// defaults
+	for _, key := range sortedKeys(o.shape) {
+		if _, ok := fields[key]; ok {
+			continue
+		}
+		if err := o.shape[key].decode(nil, opts); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	o.isSet = true
+
+	return nil
+}
+
+// CompileJSONSchema implements Schema.CompileJSONSchema
+func (o *ObjectSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error {
+	if o == nil {
+		return fmt.Errorf("found nil schema interface with JSON tag: %s", jsonTag)
+	}
+
+	objectSchema := &jsonschema.JSONSchema{
+		Type:       jsonSchemaTypes(ObjectSchemaType, o.isNullable),
+		Properties: make(map[string]*jsonschema.JSONSchema),
+		Required:   make([]string, 0),
+	}
+
+	if o.description != nil {
+		objectSchema.Description = *o.description
+	}
+	objectSchema.Default = o.defaultValue.jsonSchemaDefault()
+
+	for _, key := range o.keys {
+		if err := o.shape[key].CompileJSONSchema(objectSchema, key); err != nil {
+			return fmt.Errorf("failed to compile key %q: %w", key, err)
+		}
+	}
+
+	switch o.mode {
+	case strictUnknownKeys:
+		objectSchema.AdditionalProperties = jsonschema.Bool(false)
+
+	case catchallUnknownKeys:
+		catchallSchema, err := compileStandalone(o.catchall)
+		if err != nil {
+			return fmt.Errorf("failed to compile catchall schema: %w", err)
+		}
+		objectSchema.AdditionalProperties = catchallSchema
+	}
+
+	schema.Properties[jsonTag] = objectSchema
+	if !o.isOptional {
+		schema.Required = append(schema.Required, jsonTag)
+	}
+	return nil
+}
+
+// Clone implements Schema.Clone by creating a deep copy of the ObjectSchema,
+// including every schema of its shape
+func (o *ObjectSchema) Clone() Schema {
+	clone := &ObjectSchema{
+		schemaType:       o.schemaType,
+		shape:            make(map[string]Schema, len(o.shape)),
+		keys:             make([]string, len(o.keys)),
+		mode:             o.mode,
+		isSet:            o.isSet,
+		refinements:      make([]refinement[map[string]interface{}], len(o.refinements)),
+		superRefinements: make([]superRefinement[map[string]interface{}], len(o.superRefinements)),
+		preprocessors:    make([]preprocessFunc, len(o.preprocessors)),
+		isOptional:       o.isOptional,
+		result:           &ValidationResult{},
+	}
+
+	copy(clone.keys, o.keys)
+	copy(clone.refinements, o.refinements)
+	copy(clone.superRefinements, o.superRefinements)
+	copy(clone.preprocessors, o.preprocessors)
+	for key, schema := range o.shape {
+		clone.shape[key] = schema.Clone()
+	}
+
+	if o.catchall != nil {
+		clone.catchall = o.catchall.Clone()
+	}
+	if o.description != nil {
+		desc := *o.description
+		clone.description = &desc
+	}
+	if o.catchallEntries != nil {
+		clone.catchallEntries = make(map[string]Schema, len(o.catchallEntries))
+		for key, elem := range o.catchallEntries {
+			clone.catchallEntries[key] = elem.Clone()
+		}
+	}
+	if o.unknown != nil {
+		clone.unknown = make(map[string]interface{}, len(o.unknown))
+		for key, v := range o.unknown {
+			clone.unknown[key] = v
+		}
+	}
+
+	clone.defaultValue = o.defaultValue
+	clone.isNullable = o.isNullable
+	clone.isNull = o.isNull
+
+	return clone
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -3,32 +3,293 @@ package gsv
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 )
 
+// ParseOptions configures how Parse validates the decoded schema and how the
+// resulting ValidationResult is handed back to the caller.
 type ParseOptions struct {
 	StopOnFirst bool           // Stop validation on first error
 	SkipMissing bool           // Skip validation of missing fields
+	Strict      bool           // Report keys that aren't part of the schema
 	ErrorMode   ValidationMode // How to handle errors
+
+	// Coerce converts values of the wrong type into the type of their schema
+	// for every schema that supports it, e.g. "42" into 42 for numbers. Each
+	// coerced value is reported as a CoercionWarning.
+	Coerce bool
+
+	// Repair extracts the JSON from free text and repairs its syntax with
+	// ExtractJSON before decoding. Each repair is reported as a
+	// RepairWarning.
+	Repair bool
 }
 
+// ValidationMode denotes how validation errors are returned from Parse.
 type ValidationMode int
 
 const (
+	// ReturnAllErrors returns every validation error that was collected
 	ReturnAllErrors ValidationMode = iota
+
+	// ReturnFirstError trims the ValidationResult down to its first error
 	ReturnFirstError
+
+	// PanicOnError panics with the ValidationResult's error when validation fails
 	PanicOnError
 )
 
 // TODO - T needs to just be a gsv schema type?
 
+// Parse decodes the JSON data into the gsv schema struct t and then validates
+// every schema in it, honoring the first ParseOptions given.
+//
+// An error is only returned when the data can't be decoded into t (malformed
+// JSON or a value of the wrong type). Failed validators are reported through
+// the returned ValidationResult.
 func Parse[T any](data []byte, t *T, opts ...ParseOptions) (*ValidationResult, error) {
-	// First unmarshal the JSON
-	if err := json.Unmarshal(data, t); err != nil {
+	options := resolveParseOptions(opts)
+
+	var repairs []Repair
+	if options.Repair {
+		var err error
+		if data, repairs, err = ExtractJSON(data); err != nil {
+			return nil, err
+		}
+	}
+
+	// First decode the JSON without running any validators so that a single
+	// failing field doesn't abort decoding of the rest of the payload
+	if err := decodeValue(reflect.ValueOf(t), data, nil, &options); err != nil {
 		return nil, fmt.Errorf("could not unmarshal json: %w", err)
 	}
 
-	// todo - handle OPTS
+	result := &ValidationResult{}
+	for _, repair := range repairs {
+		result.AddWarning(repair.warning())
+	}
+
+	// Unknown keys are reported first since they're often the reason a
+	// required field is missing, e.g. a misspelled key
+	if options.Strict {
+		for _, err := range findUnknownFields(reflect.ValueOf(t), data, nil) {
+			result.AddError(err)
+		}
+	}
 
 	// Then validate the struct
-	return ensure(*t), nil
+	if !options.StopOnFirst || !result.HasErrors() {
+		validated := ensureRecursive(reflect.ValueOf(t), nil, &options)
+		result.Errors = append(result.Errors, validated.Errors...)
+		result.Warnings = append(result.Warnings, validated.Warnings...)
+	}
+
+	switch options.ErrorMode {
+	case ReturnFirstError:
+		if result.HasErrors() {
+			result.Errors = result.Errors[:1]
+		}
+
+	case PanicOnError:
+		if result.HasErrors() {
+			panic(result.Error())
+		}
+	}
+
+	return result, nil
+}
+
+// resolveParseOptions returns the first of the variadic options or the zero
+// value ParseOptions if none were given.
+func resolveParseOptions(opts []ParseOptions) ParseOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+
+	return ParseOptions{}
+}
+
+// decodeValue decodes data into v. Schemas are decoded through their decode
+// method, which stores the value without running validators, and structs are
+// walked field by field using their JSON tags. Anything else falls back to
+// encoding/json.
+func decodeValue(v reflect.Value, data []byte, path []interface{}, opts *ParseOptions) error {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		if !v.CanSet() {
+			return fmt.Errorf("cannot decode into nil %v", v.Type())
+		}
+
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+
+	if v.CanInterface() {
+		if schema, ok := v.Interface().(Schema); ok {
+			if err := schema.decode(data, opts); err != nil {
+				if len(path) > 0 {
+					return fmt.Errorf("%s: %w", jsonPointer(path), err)
+				}
+
+				return err
+			}
+
+			return nil
+		}
+	}
+
+	if !isStructOrPtrToStruct(v) {
+		if !v.CanAddr() {
+			return json.Unmarshal(data, v.Interface())
+		}
+
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if string(data) == "null" {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	return decodeStructFields(v, fields, path, opts)
+}
+
+// decodeStructFields decodes the raw JSON object fields into the exported
+// fields of the struct v, matching keys the same way encoding/json does.
+// Schemas whose key is missing are decoded as missing values so they pick up
+// their defaults.
+func decodeStructFields(v reflect.Value, fields map[string]json.RawMessage, path []interface{}, opts *ParseOptions) error {
+	for _, field := range jsonFields(v) {
+		raw, ok := lookupField(fields, field.name)
+		if !ok {
+			if err := decodeMissing(field.value, appendPath(path, field.name), opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := decodeValue(field.value, raw, appendPath(path, field.name), opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeMissing decodes every schema reachable from v as a missing value. Nil
+// pointers and anything that isn't a schema or a struct are left untouched.
+func decodeMissing(v reflect.Value, path []interface{}, opts *ParseOptions) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		if v.CanInterface() {
+			if schema, ok := v.Interface().(Schema); ok {
+				if err := schema.decode(nil, opts); err != nil {
+					return fmt.Errorf("%s: %w", jsonPointer(path), err)
+				}
+
+				return nil
+			}
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range jsonFields(v) {
+		if err := decodeMissing(field.value, appendPath(path, field.name), opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonField is an exported struct field along with its JSON key
+type jsonField struct {
+	name  string
+	value reflect.Value
+}
+
+// jsonFields returns the exported fields of the struct v along with their
+// JSON keys. Fields of embedded structs without a tag are promoted the same
+// way encoding/json does, allocating nil embedded pointers along the way.
+func jsonFields(v reflect.Value) []jsonField {
+	typ := v.Type()
+	fields := make([]jsonField, 0, v.NumField())
+
+	for i := 0; i < v.NumField(); i++ {
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(fieldType)
+		if !ok {
+			continue
+		}
+
+		field := v.Field(i)
+
+		// Embedded structs without a tag have their fields promoted
+		if fieldType.Anonymous && name == fieldType.Name && isStructOrPtrToStruct(field) {
+			if field.Kind() == reflect.Ptr && field.IsNil() {
+				if !field.CanSet() {
+					continue
+				}
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+
+			fields = append(fields, jsonFields(reflect.Indirect(field))...)
+			continue
+		}
+
+		fields = append(fields, jsonField{name: name, value: field})
+	}
+
+	return fields
+}
+
+// jsonFieldName returns the JSON key for a struct field and false if the
+// field is ignored by encoding/json.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+// lookupField finds the raw value for key, preferring an exact match and
+// falling back to the case-insensitive match encoding/json allows.
+func lookupField(fields map[string]json.RawMessage, key string) (json.RawMessage, bool) {
+	if raw, ok := fields[key]; ok {
+		return raw, true
+	}
+
+	for k, raw := range fields {
+		if strings.EqualFold(k, key) {
+			return raw, true
+		}
+	}
+
+	return nil, false
 }
@@ -1,5 +1,7 @@
 package jsonschema
 
+import "encoding/json"
+
 // JSONSchema represents the structure of a JSON Schema
 type JSONSchema struct {
 	// Metadata
@@ -8,9 +10,14 @@ type JSONSchema struct {
 	ID          string                 `json:"$id,omitempty"`
 	Schema      string                 `json:"$schema,omitempty"`
 	Definitions map[string]*JSONSchema `json:"definitions,omitempty"`
+	Default     interface{}            `json:"default,omitempty"`
 
 	// Core
-	Type string `json:"type"`
+	Type TypeList `json:"type,omitempty"`
+
+	// References
+	Ref  string                 `json:"$ref,omitempty"`
+	Defs map[string]*JSONSchema `json:"$defs,omitempty"`
 
 	// Object validators
 	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
@@ -19,6 +26,7 @@ type JSONSchema struct {
 	PatternProperties    map[string]*JSONSchema `json:"patternProperties,omitempty"`
 	MinProperties        *int                   `json:"minProperties,omitempty"`
 	MaxProperties        *int                   `json:"maxProperties,omitempty"`
+	PropertyNames        *JSONSchema            `json:"propertyNames,omitempty"`
 
 	// String validators
 	MinLength *int   `json:"minLength,omitempty"`
@@ -46,5 +54,140 @@ type JSONSchema struct {
 	AnyOf []*JSONSchema `json:"anyOf,omitempty"`
 	OneOf []*JSONSchema `json:"oneOf,omitempty"`
 	Not   *JSONSchema   `json:"not,omitempty"`
+
+	// boolean is set for the "true" and "false" boolean schemas
+	boolean *bool
+
+	// hasConst is set for a "const" keyword, which a nil Const can't tell
+	// apart from no "const" keyword
+	hasConst bool
+}
+
+// TypeList is the "type" keyword. A single type is marshaled as a string and
+// several types as an array, e.g. ["string", "null"] for nullable strings.
+type TypeList []string
+
+// Types returns the type list of the given types
+func Types(types ...string) TypeList {
+	return TypeList(types)
+}
+
+// Has reports whether typ is one of the types
+func (t TypeList) Has(typ string) bool {
+	for _, v := range t {
+		if v == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON implements json.Marshaler
+func (t TypeList) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler and accepts both a single type and
+// an array of types
+func (t *TypeList) UnmarshalJSON(data []byte) error {
+	var typ string
+	if err := json.Unmarshal(data, &typ); err == nil {
+		*t = TypeList{typ}
+		return nil
+	}
+
+	var types []string
+	if err := json.Unmarshal(data, &types); err != nil {
+		return err
+	}
+
+	*t = types
+	return nil
+}
+
+// Bool returns a boolean schema. The "true" schema accepts every instance and
+// the "false" schema rejects every instance, e.g. "additionalProperties": false
+func Bool(b bool) *JSONSchema {
+	return &JSONSchema{boolean: &b}
 }
 
+// IsBool reports whether the schema is a boolean schema and, if so, its value
+func (s *JSONSchema) IsBool() (value bool, ok bool) {
+	if s == nil || s.boolean == nil {
+		return false, false
+	}
+
+	return *s.boolean, true
+}
+
+// HasConst reports whether the schema has the "const" keyword, including a
+// "const" of null
+func (s *JSONSchema) HasConst() bool {
+	return s != nil && (s.Const != nil || s.hasConst)
+}
+
+// SetConst sets the "const" keyword, which can be null unlike setting Const
+func (s *JSONSchema) SetConst(v interface{}) {
+	s.Const = v
+	s.hasConst = true
+}
+
+// RemoveConst removes the "const" keyword
+func (s *JSONSchema) RemoveConst() {
+	s.Const = nil
+	s.hasConst = false
+}
+
+// MarshalJSON implements json.Marshaler so boolean schemas are written as
+// plain true or false
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	if s.boolean != nil {
+		return json.Marshal(*s.boolean)
+	}
+
+	type plain JSONSchema
+
+	// A null const is left out by omitempty
+	if s.hasConst && s.Const == nil {
+		return json.Marshal(struct {
+			plain
+			Const json.RawMessage `json:"const"`
+		}{plain(s), json.RawMessage("null")})
+	}
+
+	return json.Marshal(plain(s))
+}
+
+// UnmarshalJSON implements json.Unmarshaler and accepts both boolean and
+// object schemas
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = JSONSchema{boolean: &b}
+		return nil
+	}
+
+	type plain JSONSchema
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+
+	// A "const" of null is only told apart from a missing one by its raw
+	// value
+	var raw struct {
+		Const json.RawMessage `json:"const"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*s = JSONSchema(p)
+	s.hasConst = raw.Const != nil
+	return nil
+}
@@ -0,0 +1,570 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Output is the result of a validation in the "basic" output format of the
+// JSON Schema spec: a flat list of the errors found in the instance
+type Output struct {
+	Valid  bool         `json:"valid"`
+	Errors []OutputUnit `json:"errors,omitempty"`
+}
+
+// OutputUnit describes a single error of the "basic" output format
+type OutputUnit struct {
+	// KeywordLocation is the JSON pointer of the failing keyword in the
+	// schema, following any "$ref" it went through
+	KeywordLocation string `json:"keywordLocation"`
+
+	// InstanceLocation is the JSON pointer of the failing value in the
+	// instance
+	InstanceLocation string `json:"instanceLocation"`
+
+	Error string `json:"error"`
+}
+
+// ValidateJSON decodes the JSON document and validates it against the schema
+func (s *JSONSchema) ValidateJSON(data []byte) (*Output, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var instance interface{}
+	if err := decoder.Decode(&instance); err != nil {
+		return nil, fmt.Errorf("could not unmarshal instance: %w", err)
+	}
+
+	return s.Validate(instance)
+}
+
+// Validate validates an instance, as decoded by encoding/json into an
+// interface{}, against the schema. Local references are resolved from this
+// schema and "format" is only an annotation, like the spec's default.
+//
+// The returned error is only set when the schema itself can't be used, like
+// when a pattern doesn't compile or a reference can't be resolved.
+func (s *JSONSchema) Validate(instance interface{}) (*Output, error) {
+	v := &validator{
+		root:      s,
+		patterns:  make(map[string]*regexp.Regexp),
+		resolving: make(map[string]bool),
+	}
+
+	v.validate(s, instance, "", "")
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	return &Output{Valid: len(v.errors) == 0, Errors: v.errors}, nil
+}
+
+// Resolve returns the schema a local reference, like "#/$defs/address",
+// points to in this schema. Its errors leave the reference out for the
+// caller to add.
+func (s *JSONSchema) Resolve(ref string) (*JSONSchema, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("only local references are supported")
+	}
+
+	current := s
+	if pointer == "" {
+		return current, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer")
+	}
+
+	segments := strings.Split(pointer[1:], "/")
+	for i := 0; i < len(segments) && current != nil; i++ {
+		switch segment := unescapePointer(segments[i]); segment {
+		case "items":
+			current = current.Items
+		case "additionalProperties":
+			current = current.AdditionalProperties
+		case "propertyNames":
+			current = current.PropertyNames
+		case "not":
+			current = current.Not
+
+		case "properties", "definitions", "$defs", "patternProperties":
+			if i++; i == len(segments) {
+				return nil, fmt.Errorf("no schema at %s", pointer)
+			}
+			current = map[string]map[string]*JSONSchema{
+				"properties":        current.Properties,
+				"definitions":       current.Definitions,
+				"$defs":             current.Defs,
+				"patternProperties": current.PatternProperties,
+			}[segment][unescapePointer(segments[i])]
+
+		case "allOf", "anyOf", "oneOf":
+			if i++; i == len(segments) {
+				return nil, fmt.Errorf("no schema at %s", pointer)
+			}
+			schemas := map[string][]*JSONSchema{
+				"allOf": current.AllOf,
+				"anyOf": current.AnyOf,
+				"oneOf": current.OneOf,
+			}[segment]
+
+			index, err := strconv.Atoi(segments[i])
+			if err != nil || index < 0 || index >= len(schemas) {
+				return nil, fmt.Errorf("no schema at %s", pointer)
+			}
+			current = schemas[index]
+
+		default:
+			return nil, fmt.Errorf("no schema at %s", pointer)
+		}
+	}
+
+	if current == nil {
+		return nil, fmt.Errorf("no schema at %s", pointer)
+	}
+
+	return current, nil
+}
+
+// validator validates an instance and collects its errors
+type validator struct {
+	root *JSONSchema
+
+	// patterns caches the compiled patterns of the schema
+	patterns map[string]*regexp.Regexp
+
+	// resolving holds the references being validated at an instance location
+	// to detect references that loop without consuming the instance
+	resolving map[string]bool
+
+	errors []OutputUnit
+	err    error
+}
+
+// fail records an error of the keyword at the instance location
+func (v *validator) fail(keywordLocation, instanceLocation, format string, args ...interface{}) {
+	v.errors = append(v.errors, OutputUnit{
+		KeywordLocation:  keywordLocation,
+		InstanceLocation: instanceLocation,
+		Error:            fmt.Sprintf(format, args...),
+	})
+}
+
+// subschema validates the instance against a subschema without recording
+// its errors, which are returned instead
+func (v *validator) subschema(s *JSONSchema, instance interface{}, keyword, location string) []OutputUnit {
+	errors := v.errors
+	v.errors = nil
+
+	v.validate(s, instance, keyword, location)
+
+	sub := v.errors
+	v.errors = errors
+	return sub
+}
+
+// validate validates the instance at location against the schema at keyword
+func (v *validator) validate(s *JSONSchema, instance interface{}, keyword, location string) {
+	if v.err != nil || s == nil {
+		return
+	}
+
+	if valid, ok := s.IsBool(); ok {
+		if !valid {
+			v.fail(keyword, location, "no value is allowed")
+		}
+		return
+	}
+
+	if s.Ref != "" {
+		v.validateRef(s.Ref, instance, pointer(keyword, "$ref"), location)
+	}
+
+	if len(s.Type) > 0 && !matchesType(s.Type, instance) {
+		v.fail(pointer(keyword, "type"), location, "must be of type %s, got %s",
+			strings.Join(s.Type, " or "), typeOf(instance))
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, value := range s.Enum {
+			if equal(value, instance) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(pointer(keyword, "enum"), location, "must be one of %s", marshal(s.Enum))
+		}
+	}
+
+	if s.HasConst() && !equal(s.Const, instance) {
+		v.fail(pointer(keyword, "const"), location, "must be %s", marshal(s.Const))
+	}
+
+	v.validateCombinators(s, instance, keyword, location)
+
+	switch value := instance.(type) {
+	case string:
+		v.validateString(s, value, keyword, location)
+	case []interface{}:
+		v.validateArray(s, value, keyword, location)
+	case map[string]interface{}:
+		v.validateObject(s, value, keyword, location)
+	default:
+		if n, ok := number(instance); ok {
+			v.validateNumber(s, n, keyword, location)
+		}
+	}
+}
+
+// validateRef validates the instance against the schema a local reference
+// points to
+func (v *validator) validateRef(ref string, instance interface{}, keyword, location string) {
+	target, err := v.root.Resolve(ref)
+	if err != nil {
+		v.err = fmt.Errorf("could not resolve $ref %q at %s: %w", ref, keyword, err)
+		return
+	}
+
+	key := ref + " " + location
+	if v.resolving[key] {
+		v.err = fmt.Errorf("$ref %s at %s loops without validating a nested value", ref, keyword)
+		return
+	}
+
+	v.resolving[key] = true
+	defer delete(v.resolving, key)
+
+	v.validate(target, instance, keyword, location)
+}
+
+// validateCombinators validates "allOf", "anyOf", "oneOf" and "not"
+func (v *validator) validateCombinators(s *JSONSchema, instance interface{}, keyword, location string) {
+	for i, sub := range s.AllOf {
+		v.validate(sub, instance, pointer(keyword, "allOf", i), location)
+	}
+
+	if len(s.AnyOf) > 0 {
+		var errors []OutputUnit
+		matched := false
+		for i, sub := range s.AnyOf {
+			subErrors := v.subschema(sub, instance, pointer(keyword, "anyOf", i), location)
+			if len(subErrors) == 0 {
+				matched = true
+				break
+			}
+			errors = append(errors, subErrors...)
+		}
+		if !matched {
+			v.fail(pointer(keyword, "anyOf"), location, "must match at least one schema")
+			v.errors = append(v.errors, errors...)
+		}
+	}
+
+	if len(s.OneOf) > 0 {
+		var (
+			errors  []OutputUnit
+			matches []string
+		)
+		for i, sub := range s.OneOf {
+			subErrors := v.subschema(sub, instance, pointer(keyword, "oneOf", i), location)
+			if len(subErrors) == 0 {
+				matches = append(matches, strconv.Itoa(i))
+			}
+			errors = append(errors, subErrors...)
+		}
+		switch len(matches) {
+		case 0:
+			v.fail(pointer(keyword, "oneOf"), location, "must match exactly one schema")
+			v.errors = append(v.errors, errors...)
+		case 1:
+		default:
+			v.fail(pointer(keyword, "oneOf"), location, "must match exactly one schema, matched schemas %s",
+				strings.Join(matches, ", "))
+		}
+	}
+
+	if s.Not != nil && len(v.subschema(s.Not, instance, pointer(keyword, "not"), location)) == 0 {
+		v.fail(pointer(keyword, "not"), location, "must not match the schema")
+	}
+}
+
+// validateString validates the length and pattern of a string
+func (v *validator) validateString(s *JSONSchema, value, keyword, location string) {
+	length := utf8.RuneCountInString(value)
+
+	if s.MinLength != nil && length < *s.MinLength {
+		v.fail(pointer(keyword, "minLength"), location, "must be at least %d characters long", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(pointer(keyword, "maxLength"), location, "must be at most %d characters long", *s.MaxLength)
+	}
+
+	if s.Pattern != "" {
+		re := v.pattern(s.Pattern, pointer(keyword, "pattern"))
+		if re != nil && !re.MatchString(value) {
+			v.fail(pointer(keyword, "pattern"), location, "must match pattern %s", s.Pattern)
+		}
+	}
+}
+
+// validateNumber validates the bounds of a number
+func (v *validator) validateNumber(s *JSONSchema, n float64, keyword, location string) {
+	if s.Minimum != nil && n < *s.Minimum {
+		v.fail(pointer(keyword, "minimum"), location, "must be greater than or equal to %v", *s.Minimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		v.fail(pointer(keyword, "maximum"), location, "must be less than or equal to %v", *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+		v.fail(pointer(keyword, "exclusiveMinimum"), location, "must be greater than %v", *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
+		v.fail(pointer(keyword, "exclusiveMaximum"), location, "must be less than %v", *s.ExclusiveMaximum)
+	}
+
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		quotient := n / *s.MultipleOf
+		if math.Abs(quotient-math.Round(quotient)) >= 1e-9 {
+			v.fail(pointer(keyword, "multipleOf"), location, "must be a multiple of %v", *s.MultipleOf)
+		}
+	}
+}
+
+// validateArray validates the bounds of an array and its items
+func (v *validator) validateArray(s *JSONSchema, items []interface{}, keyword, location string) {
+	if s.MinItems != nil && len(items) < *s.MinItems {
+		v.fail(pointer(keyword, "minItems"), location, "must have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(items) > *s.MaxItems {
+		v.fail(pointer(keyword, "maxItems"), location, "must have at most %d items", *s.MaxItems)
+	}
+
+	if s.UniqueItems != nil && *s.UniqueItems {
+	unique:
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				if equal(items[i], items[j]) {
+					v.fail(pointer(keyword, "uniqueItems"), location,
+						"must not have duplicate items, items %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+
+	if s.Items != nil {
+		for i, item := range items {
+			v.validate(s.Items, item, pointer(keyword, "items"), pointer(location, i))
+		}
+	}
+}
+
+// validateObject validates the properties of an object
+func (v *validator) validateObject(s *JSONSchema, object map[string]interface{}, keyword, location string) {
+	if s.MinProperties != nil && len(object) < *s.MinProperties {
+		v.fail(pointer(keyword, "minProperties"), location, "must have at least %d properties", *s.MinProperties)
+	}
+	if s.MaxProperties != nil && len(object) > *s.MaxProperties {
+		v.fail(pointer(keyword, "maxProperties"), location, "must have at most %d properties", *s.MaxProperties)
+	}
+
+	for _, key := range s.Required {
+		if _, ok := object[key]; !ok {
+			v.fail(pointer(keyword, "required"), location, "missing required property %q", key)
+		}
+	}
+
+	for _, key := range sortedKeys(object) {
+		value := object[key]
+		propertyLocation := pointer(location, key)
+
+		if s.PropertyNames != nil {
+			v.validate(s.PropertyNames, key, pointer(keyword, "propertyNames"), propertyLocation)
+		}
+
+		evaluated := false
+		if property, ok := s.Properties[key]; ok {
+			evaluated = true
+			v.validate(property, value, pointer(keyword, "properties", key), propertyLocation)
+		}
+
+		for _, pattern := range sortedKeys(s.PatternProperties) {
+			re := v.pattern(pattern, pointer(keyword, "patternProperties", pattern))
+			if re != nil && re.MatchString(key) {
+				evaluated = true
+				v.validate(s.PatternProperties[pattern], value, pointer(keyword, "patternProperties", pattern), propertyLocation)
+			}
+		}
+
+		if !evaluated && s.AdditionalProperties != nil {
+			if allowed, ok := s.AdditionalProperties.IsBool(); ok && !allowed {
+				v.fail(pointer(keyword, "additionalProperties"), propertyLocation, "property %q is not allowed", key)
+				continue
+			}
+			v.validate(s.AdditionalProperties, value, pointer(keyword, "additionalProperties"), propertyLocation)
+		}
+	}
+}
+
+// pattern returns the compiled pattern, failing the validation if it
+// doesn't compile
+func (v *validator) pattern(pattern, keyword string) *regexp.Regexp {
+	if re, ok := v.patterns[pattern]; ok {
+		return re
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		v.err = fmt.Errorf("invalid pattern at %s: %w", keyword, err)
+		return nil
+	}
+
+	v.patterns[pattern] = re
+	return re
+}
+
+// matchesType reports whether the instance is of one of the types
+func matchesType(types TypeList, instance interface{}) bool {
+	typ := typeOf(instance)
+	if types.Has(typ) {
+		return true
+	}
+
+	if typ == "number" && types.Has("integer") {
+		n, _ := number(instance)
+		return n == math.Trunc(n) && !math.IsInf(n, 0)
+	}
+
+	return false
+}
+
+// typeOf returns the JSON type of a decoded value
+func typeOf(instance interface{}) string {
+	switch instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	if _, ok := number(instance); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", instance)
+}
+
+// number returns the value of a JSON number, decoded as a json.Number or a
+// Go number
+func number(v interface{}) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// equal reports whether two decoded JSON values are equal, comparing numbers
+// by value
+func equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+
+	switch a := a.(type) {
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return a == b
+	}
+}
+
+// pointer appends the tokens to a JSON pointer
+func pointer(base string, tokens ...interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(base)
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(escapePointer(fmt.Sprint(token)))
+	}
+	return sb.String()
+}
+
+// escapePointer encodes a segment of a JSON pointer
+func escapePointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
+
+// unescapePointer decodes a segment of a JSON pointer
+func unescapePointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+}
+
+// marshal returns the JSON of a value for error messages
+func marshal(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
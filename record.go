@@ -0,0 +1,565 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+const (
+	RequiredRecordError     ValidationErrorType = "required_record"
+	MinPropertiesError      ValidationErrorType = "min_properties"
+	MaxPropertiesError      ValidationErrorType = "max_properties"
+	InvalidRecordKeyError   ValidationErrorType = "invalid_record_key"
+	InvalidRecordValueError ValidationErrorType = "invalid_record_value"
+)
+
+// RecordSchema implements the Schema interface for JSON objects with dynamic
+// keys, like {"headers": {"X-Foo": "bar"}}. Every key is validated against the
+// key schema and every value against the value schema.
+type RecordSchema struct {
+	keySchema   Schema
+	valueSchema Schema
+
+	minProperties *int
+	maxProperties *int
+
+	// keyPattern must match every key
+	keyPattern *regexp.Regexp
+
+	// messages override the default messages of the property count and
+	// key pattern validators
+	minMessage     string
+	maxMessage     string
+	patternMessage string
+
+	value map[string]interface{}
+
+	// entries hold the value schemas of the decoded value, so errors of their
+	// transforms aren't lost, and are nil for values given to Set or setValue
+	entries map[string]Schema
+
+	// preprocessors rewrite the JSON value before it's decoded
+	preprocessors []preprocessFunc
+
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[map[string]interface{}]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
+
+	// refinements are the custom validations of the whole record
+	refinements []refinement[map[string]interface{}]
+
+	description *string
+
+	// isOptional denotes if the record in the schema is optional
+	isOptional bool
+
+	// the result of the last validation
+	result *ValidationResult
+}
+
+// Record creates a new schema for objects whose keys match the key schema and
+// whose values match the value schema. Keys are always strings in JSON so the
+// key schema must accept strings, e.g. a StringSchema or an Enum of strings.
+func Record(keySchema, valueSchema Schema) *RecordSchema {
+	if keySchema == nil {
+		panic("keySchema cannot be nil")
+	}
+	if valueSchema == nil {
+		panic("valueSchema cannot be nil")
+	}
+
+	return &RecordSchema{
+		keySchema:   keySchema,
+		valueSchema: valueSchema,
+		result:      &ValidationResult{},
+	}
+}
+
+// MinProperties sets the minimum number of keys
+func (r *RecordSchema) MinProperties(min int, opts ...ValidationOptions) *RecordSchema {
+	if min < 0 {
+		panic("minProperties cannot be negative")
+	}
+	r.minProperties = &min
+	if len(opts) > 0 {
+		r.minMessage = opts[0].Message
+	}
+	return r
+}
+
+// MaxProperties sets the maximum number of keys
+func (r *RecordSchema) MaxProperties(max int, opts ...ValidationOptions) *RecordSchema {
+	if max < 0 {
+		panic("maxProperties cannot be negative")
+	}
+	r.maxProperties = &max
+	if len(opts) > 0 {
+		r.maxMessage = opts[0].Message
+	}
+	return r
+}
+
+// KeyPattern requires every key to match the regular expression. It panics
+// if the pattern doesn't compile.
+func (r *RecordSchema) KeyPattern(pattern string, opts ...ValidationOptions) *RecordSchema {
+	r.keyPattern = regexp.MustCompile(pattern)
+	if len(opts) > 0 {
+		r.patternMessage = opts[0].Message
+	}
+	return r
+}
+
+// Refine adds a custom validation of the whole record. It only runs once the
+// property count and every key and value are valid.
+func (r *RecordSchema) Refine(check func(map[string]interface{}) bool, opts ...ValidationOptions) *RecordSchema {
+	r.refinements = append(r.refinements, newRefinement(check, opts))
+	return r
+}
+
+// Preprocess adds a function that rewrites the decoded JSON value before it's
+// decoded as a record
+func (r *RecordSchema) Preprocess(fn func(interface{}) interface{}) *RecordSchema {
+	r.preprocessors = append(r.preprocessors, fn)
+	return r
+}
+
+// Description sets the description of the record
+func (r *RecordSchema) Description(val string) *RecordSchema {
+	r.description = &val
+	return r
+}
+
+// Nullable marks JSON null as a valid value of the record. Unlike Optional,
+// the key must still be present unless the record is optional as well.
+func (r *RecordSchema) Nullable() *RecordSchema {
+	r.isNullable = true
+	return r
+}
+
+// IsNullable implements Schema.IsNullable
+func (r *RecordSchema) IsNullable() bool {
+	return r.isNullable
+}
+
+// State implements Schema.State
+func (r *RecordSchema) State() ValueState {
+	_, ok := r.getValue()
+	return valueState(r.isNull, ok)
+}
+
+// Default marks the record field as optional and sets the value it takes when
+// it's missing
+func (r *RecordSchema) Default(v map[string]interface{}) *RecordSchema {
+	r.defaultValue = newDefault(v)
+	r.isOptional = true
+	return r
+}
+
+// DefaultFunc is Default with a value built by fn for every missing record
+func (r *RecordSchema) DefaultFunc(fn func() map[string]interface{}) *RecordSchema {
+	r.defaultValue = newDefaultFunc(fn)
+	r.isOptional = true
+	return r
+}
+
+// Optional marks the record field as optional
+func (r *RecordSchema) Optional() *RecordSchema {
+	r.isOptional = true
+	return r
+}
+
+// IsOptional implements Schema.IsOptional
+func (r *RecordSchema) IsOptional() bool {
+	return r.isOptional
+}
+
+// Set provides a way to set the record's values. Each value must be of the
+// type the value schema expects.
+func (r *RecordSchema) Set(values map[string]interface{}) *RecordSchema {
+	r.isNull = false
+	r.result = &ValidationResult{}
+
+	r.value = make(map[string]interface{}, len(values))
+	r.entries = nil
+	for _, key := range sortedKeys(values) {
+		elem := r.valueSchema.Clone()
+		if err := elem.setValue(values[key]); err != nil {
+			r.result.AddError(&ValidationError{
+				Type:    InvalidRecordValueError,
+				Field:   jsonPointer([]interface{}{key}),
+				Path:    []interface{}{key},
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		if elemVal, ok := elem.getValue(); ok {
+			r.value[key] = elemVal
+		}
+	}
+
+	return r
+}
+
+func (r *RecordSchema) setValue(val interface{}) error {
+	if val == nil && r.isNullable {
+		r.value = nil
+		r.entries = nil
+		r.isNull = true
+		return nil
+	}
+	r.isNull = false
+
+	values, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected object value, got %T", val)
+	}
+	r.value = values
+	r.entries = nil
+	return nil
+}
+
+func (r *RecordSchema) getValue() (interface{}, bool) {
+	if r.isNull {
+		return nil, true
+	}
+
+	if r.value == nil {
+		return nil, false
+	}
+	return r.value, true
+}
+
+// Value returns the record's values keyed by their JSON key. This method
+// returns (nil, false) if the record hasn't been set.
+func (r *RecordSchema) Value() (map[string]interface{}, bool) {
+	val, ok := r.getValue()
+	if !ok || val == nil {
+		return nil, false
+	}
+	recordVal, ok := val.(map[string]interface{})
+	if !ok {
+		panic(fmt.Sprintf("RecordSchema: invalid internal value type %T, expected map[string]interface{}", val))
+	}
+	return recordVal, true
+}
+
+// Validate performs the validation
+func (r *RecordSchema) Validate() *ValidationResult {
+	return r.validate(&ParseOptions{})
+}
+
+func (r *RecordSchema) validate(opts *ParseOptions) *ValidationResult {
+	r.result = &ValidationResult{}
+
+	if r.isNull {
+		if !r.isNullable {
+			r.result.AddError(newNullError())
+		}
+		return r.result
+	}
+
+	if r.value == nil {
+		if !r.isOptional {
+			r.result.AddError(&ValidationError{
+				Type:    RequiredRecordError,
+				Message: "record is required",
+			})
+		}
+		return r.result
+	}
+
+	if r.minProperties != nil && len(r.value) < *r.minProperties {
+		r.result.AddError(&ValidationError{
+			Type:     MinPropertiesError,
+			Message:  messageOr(r.minMessage, fmt.Sprintf("minimum %d properties required", *r.minProperties)),
+			Expected: *r.minProperties,
+			Actual:   len(r.value),
+		})
+	}
+
+	if r.maxProperties != nil && len(r.value) > *r.maxProperties {
+		r.result.AddError(&ValidationError{
+			Type:     MaxPropertiesError,
+			Message:  messageOr(r.maxMessage, fmt.Sprintf("maximum %d properties allowed", *r.maxProperties)),
+			Expected: *r.maxProperties,
+			Actual:   len(r.value),
+		})
+	}
+
+	if opts.StopOnFirst && r.result.HasErrors() {
+		return r.result
+	}
+
+	for _, key := range sortedKeys(r.value) {
+		r.validateKey(key, opts)
+		if opts.StopOnFirst && r.result.HasErrors() {
+			return r.result
+		}
+
+		elem, err := r.entry(key)
+		if err != nil {
+			r.result.AddError(&ValidationError{
+				Type:    InvalidRecordValueError,
+				Field:   jsonPointer([]interface{}{key}),
+				Path:    []interface{}{key},
+				Message: err.Error(),
+			})
+		} else {
+			r.addErrors(key, elem.validate(opts))
+		}
+
+		if opts.StopOnFirst && r.result.HasErrors() {
+			return r.result
+		}
+	}
+
+	runRefinements(r.refinements, r.value, r.result, opts)
+
+	return r.result
+}
+
+// entry returns the schema holding the value at key, either the one it was
+// decoded with or a new one holding the value
+func (r *RecordSchema) entry(key string) (Schema, error) {
+	if elem, ok := r.entries[key]; ok {
+		return elem, nil
+	}
+
+	elem := r.valueSchema.Clone()
+	if err := elem.setValue(r.value[key]); err != nil {
+		return nil, err
+	}
+
+	return elem, nil
+}
+
+// validateKey validates a key against the key pattern and the key schema.
+// Errors are reported at the key itself.
+func (r *RecordSchema) validateKey(key string, opts *ParseOptions) {
+	path := []interface{}{key}
+
+	if r.keyPattern != nil && !r.keyPattern.MatchString(key) {
+		r.result.AddError(&ValidationError{
+			Type:     InvalidRecordKeyError,
+			Field:    jsonPointer(path),
+			Path:     path,
+			Message:  messageOr(r.patternMessage, fmt.Sprintf("key must match pattern %s", r.keyPattern)),
+			Expected: r.keyPattern.String(),
+			Actual:   key,
+		})
+		if opts.StopOnFirst {
+			return
+		}
+	}
+
+	keySchema := r.keySchema.Clone()
+	if err := keySchema.setValue(key); err != nil {
+		r.result.AddError(&ValidationError{
+			Type:    InvalidRecordKeyError,
+			Field:   jsonPointer(path),
+			Path:    path,
+			Message: err.Error(),
+			Actual:  key,
+		})
+		return
+	}
+
+	r.addErrors(key, keySchema.validate(opts))
+}
+
+// addErrors adds the errors and warnings of the key or value at key to the
+// record's result
+func (r *RecordSchema) addErrors(key string, result *ValidationResult) {
+	for _, err := range result.Errors {
+		err.prependPath(key)
+		r.result.AddError(err)
+	}
+	r.result.addWarnings(result.Warnings, key)
+}
+
+// MarshalJSON implements json.Marshaler
+func (r *RecordSchema) MarshalJSON() ([]byte, error) {
+	if r.isNull {
+		return json.Marshal(nil)
+	}
+
+	if r.value == nil {
+		if r.isOptional {
+			return json.Marshal(nil)
+		}
+		return nil, fmt.Errorf("required record has no value")
+	}
+	return json.Marshal(r.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *RecordSchema) UnmarshalJSON(data []byte) error {
+	if err := r.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	return r.Validate().Error()
+}
+
+func (r *RecordSchema) decode(data []byte, opts *ParseOptions) error {
+	data, err := preprocess(data, r.preprocessors)
+	if err != nil {
+		return fmt.Errorf("invalid record value: %w", err)
+	}
+
+	r.entries = nil
+
+	r.isNull = string(data) == "null"
+
+	if len(data) == 0 || string(data) == "null" {
+		r.value = nil
+		applyDefault(r, r.defaultValue)
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("invalid record value: %w", err)
+	}
+
+	value := make(map[string]interface{}, len(fields))
+	entries := make(map[string]Schema, len(fields))
+	for _, key := range sortedKeys(fields) {
+		elem := r.valueSchema.Clone()
+		if err := elem.decode(fields[key], opts); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+
+		val, ok := elem.getValue()
+		if !ok {
+			return fmt.Errorf("%s: missing value", key)
+		}
+
+		value[key] = val
+		entries[key] = elem
+	}
+
+	r.value = value
+	r.entries = entries
+
+	return nil
+}
+
+// CompileJSONSchema implements Schema.CompileJSONSchema. Values compile to
+// "additionalProperties", or to "patternProperties" when there's a key
+// pattern, and key schemas with constraints compile to "propertyNames".
+func (r *RecordSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error {
+	if r == nil {
+		return fmt.Errorf("found nil schema interface with JSON tag: %s", jsonTag)
+	}
+
+	valueSchema, err := compileStandalone(r.valueSchema)
+	if err != nil {
+		return fmt.Errorf("failed to compile value schema: %w", err)
+	}
+
+	keySchema, err := compileStandalone(r.keySchema)
+	if err != nil {
+		return fmt.Errorf("failed to compile key schema: %w", err)
+	}
+
+	recordSchema := &jsonschema.JSONSchema{
+		Type:          jsonSchemaTypes(ObjectSchemaType, r.isNullable),
+		MinProperties: r.minProperties,
+		MaxProperties: r.maxProperties,
+	}
+
+	if r.description != nil {
+		recordSchema.Description = *r.description
+	}
+	recordSchema.Default = r.defaultValue.jsonSchemaDefault()
+
+	if r.keyPattern != nil {
+		recordSchema.PatternProperties = map[string]*jsonschema.JSONSchema{
+			r.keyPattern.String(): valueSchema,
+		}
+		recordSchema.AdditionalProperties = jsonschema.Bool(false)
+	} else {
+		recordSchema.AdditionalProperties = valueSchema
+	}
+
+	// Every JSON key is a string so a plain string key schema adds nothing
+	keySchema.Description = ""
+	if !reflect.DeepEqual(keySchema, &jsonschema.JSONSchema{Type: jsonschema.Types(StringSchemaType)}) {
+		recordSchema.PropertyNames = keySchema
+	}
+
+	schema.Properties[jsonTag] = recordSchema
+	if !r.isOptional {
+		schema.Required = append(schema.Required, jsonTag)
+	}
+	return nil
+}
+
+// Clone implements Schema.Clone by creating a deep copy of the RecordSchema
+func (r *RecordSchema) Clone() Schema {
+	clone := &RecordSchema{
+		keySchema:      r.keySchema.Clone(),
+		valueSchema:    r.valueSchema.Clone(),
+		keyPattern:     r.keyPattern,
+		minMessage:     r.minMessage,
+		maxMessage:     r.maxMessage,
+		patternMessage: r.patternMessage,
+		refinements:    make([]refinement[map[string]interface{}], len(r.refinements)),
+		preprocessors:  make([]preprocessFunc, len(r.preprocessors)),
+		isOptional:     r.isOptional,
+		result:         &ValidationResult{},
+	}
+
+	copy(clone.refinements, r.refinements)
+	copy(clone.preprocessors, r.preprocessors)
+
+	if r.entries != nil {
+		clone.entries = make(map[string]Schema, len(r.entries))
+		for key, elem := range r.entries {
+			clone.entries[key] = elem.Clone()
+		}
+	}
+
+	if r.minProperties != nil {
+		min := *r.minProperties
+		clone.minProperties = &min
+	}
+	if r.maxProperties != nil {
+		max := *r.maxProperties
+		clone.maxProperties = &max
+	}
+	if r.value != nil {
+		clone.value = make(map[string]interface{}, len(r.value))
+		for key, v := range r.value {
+			clone.value[key] = v
+		}
+	}
+	if r.description != nil {
+		desc := *r.description
+		clone.description = &desc
+	}
+
+	clone.defaultValue = r.defaultValue
+	clone.isNullable = r.isNullable
+	clone.isNull = r.isNull
+
+	return clone
+}
+
+// messageOr returns message if it's set, otherwise the fallback
+func messageOr(message, fallback string) string {
+	if message != "" {
+		return message
+	}
+	return fallback
+}
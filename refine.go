@@ -0,0 +1,95 @@
+package gsv
+
+const (
+	CustomError ValidationErrorType = "custom"
+)
+
+// refinement is a custom validation function registered through Refine. It
+// returns a ValidationError if the value is invalid.
+type refinement[T any] func(T) *ValidationError
+
+// newRefinement wraps the check of a Refine call. A failed check reports a
+// CustomError with the message given in the options.
+func newRefinement[T any](check func(T) bool, opts []ValidationOptions) refinement[T] {
+	if check == nil {
+		panic("refine check cannot be nil")
+	}
+
+	message := "invalid value"
+	if len(opts) > 0 && opts[0].Message != "" {
+		message = opts[0].Message
+	}
+
+	return func(v T) *ValidationError {
+		if !check(v) {
+			return &ValidationError{
+				Type:    CustomError,
+				Message: message,
+				Actual:  v,
+			}
+		}
+		return nil
+	}
+}
+
+// runRefinements adds the errors of the refinements for the value to the
+// result. Refinements of composite schemas only run once everything else is
+// valid, so they can rely on the shape of the value.
+func runRefinements[T any](refinements []refinement[T], v T, result *ValidationResult, opts *ParseOptions) {
+	if result.HasErrors() {
+		return
+	}
+
+	for _, refine := range refinements {
+		if err := refine(v); err != nil {
+			result.AddError(err)
+
+			if opts.StopOnFirst {
+				return
+			}
+		}
+	}
+}
+
+// RefinementContext collects the errors added by a SuperRefine function
+type RefinementContext struct {
+	errors []*ValidationError
+}
+
+// AddIssue adds a validation error. Its Path is relative to the refined
+// schema, e.g. []interface{}{"confirm"} for a key of the refined object, and
+// its Type defaults to CustomError.
+func (c *RefinementContext) AddIssue(err *ValidationError) {
+	if err.Type == "" {
+		err.Type = CustomError
+	}
+
+	err.Path = appendPath(nil, err.Path...)
+	err.Field = jsonPointer(err.Path)
+
+	c.errors = append(c.errors, err)
+}
+
+// superRefinement is a validation function registered through SuperRefine
+type superRefinement[T any] func(ctx *RefinementContext, v T)
+
+// runSuperRefinements adds the errors of the super refinements for the value
+// to the result. Like refinements, they only run once everything else is valid.
+func runSuperRefinements[T any](refinements []superRefinement[T], v T, result *ValidationResult, opts *ParseOptions) {
+	if result.HasErrors() {
+		return
+	}
+
+	for _, refine := range refinements {
+		ctx := &RefinementContext{}
+		refine(ctx, v)
+
+		for _, err := range ctx.errors {
+			result.AddError(err)
+		}
+
+		if opts.StopOnFirst && result.HasErrors() {
+			return
+		}
+	}
+}
@@ -5,14 +5,12 @@ import (
 )
 
 // Helper functions
-func isStringSchema(field reflect.Value) bool {
-	_, ok := field.Interface().(*StringSchema)
-
-	return ok
-}
+func isSchema(field reflect.Value) bool {
+	if !field.CanInterface() {
+		return false
+	}
 
-func isIntSchema(field reflect.Value) bool {
-	_, ok := field.Interface().(*IntSchema)
+	_, ok := field.Interface().(Schema)
 
 	return ok
 }
@@ -26,3 +24,51 @@ func isStructOrPtrToStruct(field reflect.Value) bool {
 
 	return typ.Kind() == reflect.Struct
 }
+
+// cloneStruct returns a copy of the gsv schema struct v with every schema in
+// it cloned, so the copy can be parsed without affecting v. Fields that
+// aren't schemas are copied as is.
+func cloneStruct[T any](v *T) *T {
+	clone := new(T)
+	*clone = *v
+
+	cloneFields(reflect.ValueOf(clone).Elem())
+
+	return clone
+}
+
+// cloneFields replaces every schema in the struct v with a clone, walking
+// into nested structs
+func cloneFields(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		switch {
+		case isSchema(field):
+			if field.IsNil() {
+				continue
+			}
+
+			clone := reflect.ValueOf(field.Interface().(Schema).Clone())
+			if clone.Type().AssignableTo(field.Type()) {
+				field.Set(clone)
+			}
+
+		case field.Kind() == reflect.Struct:
+			cloneFields(field)
+
+		case field.Kind() == reflect.Ptr && isStructOrPtrToStruct(field):
+			if field.IsNil() {
+				continue
+			}
+
+			nested := reflect.New(field.Type().Elem())
+			nested.Elem().Set(field.Elem())
+			cloneFields(nested.Elem())
+			field.Set(nested)
+		}
+	}
+}
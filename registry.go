@@ -0,0 +1,300 @@
+package gsv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ToolHandler handles a call of a tool with its validated arguments. The
+// returned value is sent back to the model: strings and json.RawMessage as
+// is and anything else marshaled to JSON.
+type ToolHandler[T any] func(ctx context.Context, args *T) (any, error)
+
+// Registry dispatches tool calls by name. Each call is parsed into a clone
+// of the tool's schema struct and the handler only runs if the arguments are
+// valid, so calls can be dispatched concurrently.
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]registeredTool
+	names []string
+
+	// opts are the options every call is parsed with
+	opts ParseOptions
+}
+
+// registeredTool is a tool with its handler, type erased so tools of any
+// schema struct can share the registry
+type registeredTool struct {
+	anthropic func() (*AnthropicTool, error)
+	openAI    func() (*OpenAITool, error)
+	call      func(ctx context.Context, arguments []byte, opts ParseOptions) (*ToolResult, error)
+}
+
+// NewRegistry creates an empty registry. Every call is parsed with the first
+// ParseOptions given.
+func NewRegistry(opts ...ParseOptions) *Registry {
+	return &Registry{
+		tools: make(map[string]registeredTool),
+		opts:  resolveParseOptions(opts),
+	}
+}
+
+// Register adds the tool to the registry. Its schema struct is only used as
+// a template and is never parsed into. Register panics if the name is
+// invalid or already registered, or if the handler is nil.
+func Register[T any](r *Registry, tool *Tool[T], handler ToolHandler[T]) {
+	if !toolNamePattern.MatchString(tool.Name) {
+		panic(fmt.Sprintf("invalid tool name %q: must match %s", tool.Name, toolNamePattern))
+	}
+	if handler == nil {
+		panic(fmt.Sprintf("tool %s has no handler", tool.Name))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tools[tool.Name]; ok {
+		panic(fmt.Sprintf("tool %s is already registered", tool.Name))
+	}
+
+	r.tools[tool.Name] = registeredTool{
+		anthropic: tool.Anthropic,
+		openAI:    tool.OpenAI,
+		call: func(ctx context.Context, arguments []byte, opts ParseOptions) (*ToolResult, error) {
+			call := &Tool[T]{Name: tool.Name, Schema: cloneStruct(tool.Schema)}
+
+			result, err := call.parse(arguments, []ParseOptions{opts})
+			if err != nil {
+				return newToolErrorResult(tool.Name, &ToolError{
+					Message: fmt.Sprintf("invalid arguments for tool %s: %s", tool.Name, err),
+				}), nil
+			}
+			if result.HasErrors() {
+				return newToolErrorResult(tool.Name, newValidationToolError(tool.Name, result)), nil
+			}
+
+			value, err := handler(ctx, call.Schema)
+			if err != nil {
+				return nil, fmt.Errorf("tool %s failed: %w", tool.Name, err)
+			}
+
+			return newToolResult(tool.Name, value)
+		},
+	}
+	r.names = append(r.names, tool.Name)
+}
+
+// Dispatch calls the tool with the JSON arguments. Calls the model can
+// correct, like unknown tools and invalid arguments, produce a ToolResult
+// holding a ToolError. An error is only returned if the handler fails or its
+// value can't be marshaled.
+func (r *Registry) Dispatch(ctx context.Context, name string, arguments []byte) (*ToolResult, error) {
+	r.mu.RLock()
+	tool, ok := r.tools[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return newToolErrorResult(name, &ToolError{
+			Message: fmt.Sprintf("unknown tool %q, available tools: %s", name, strings.Join(r.sortedNames(), ", ")),
+		}), nil
+	}
+
+	return tool.call(ctx, arguments, r.opts)
+}
+
+// DispatchAnthropic dispatches a tool_use content block. The result is
+// linked to the block through its ID.
+func (r *Registry) DispatchAnthropic(ctx context.Context, toolUse *AnthropicToolUse) (*ToolResult, error) {
+	result, err := r.Dispatch(ctx, toolUse.Name, toolUse.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	result.CallID = toolUse.ID
+	return result, nil
+}
+
+// DispatchOpenAI dispatches an entry of tool_calls. The result is linked to
+// the call through its ID.
+func (r *Registry) DispatchOpenAI(ctx context.Context, toolCall *OpenAIToolCall) (*ToolResult, error) {
+	result, err := r.Dispatch(ctx, toolCall.Function.Name, []byte(toolCall.Function.Arguments))
+	if err != nil {
+		return nil, err
+	}
+
+	result.CallID = toolCall.ID
+	return result, nil
+}
+
+// AnthropicTools renders the definitions of every tool in the order they were
+// registered
+func (r *Registry) AnthropicTools() ([]*AnthropicTool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]*AnthropicTool, 0, len(r.names))
+	for _, name := range r.names {
+		def, err := r.tools[name].anthropic()
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+
+	return defs, nil
+}
+
+// OpenAITools renders the definitions of every tool in the order they were
+// registered
+func (r *Registry) OpenAITools() ([]*OpenAITool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]*OpenAITool, 0, len(r.names))
+	for _, name := range r.names {
+		def, err := r.tools[name].openAI()
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+
+	return defs, nil
+}
+
+// sortedNames returns the names of every tool in alphabetical order
+func (r *Registry) sortedNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.names))
+	copy(names, r.names)
+	sort.Strings(names)
+
+	return names
+}
+
+// ToolResult is the outcome of a dispatched tool call, ready to be sent back
+// to the model
+type ToolResult struct {
+	// Tool is the name of the called tool
+	Tool string
+
+	// CallID is the ID of the tool call, set by DispatchAnthropic and
+	// DispatchOpenAI
+	CallID string
+
+	// Content is the handler's value or, for failed calls, the marshaled
+	// ToolError
+	Content string
+
+	// IsError denotes the call failed before reaching the handler. Error
+	// holds the reason.
+	IsError bool
+	Error   *ToolError
+}
+
+// ToolError describes a tool call that failed before reaching the handler,
+// written for the model to correct its call
+type ToolError struct {
+	Message string           `json:"error"`
+	Fields  []ToolFieldError `json:"fields,omitempty"`
+}
+
+// ToolFieldError is a validation error of a single argument
+type ToolFieldError struct {
+	Field   string              `json:"field,omitempty"`
+	Type    ValidationErrorType `json:"type"`
+	Message string              `json:"message"`
+}
+
+// newValidationToolError converts the errors of the arguments into a
+// ToolError
+func newValidationToolError(name string, result *ValidationResult) *ToolError {
+	toolErr := &ToolError{
+		Message: fmt.Sprintf("invalid arguments for tool %s", name),
+		Fields:  make([]ToolFieldError, 0, len(result.Errors)),
+	}
+
+	for _, err := range result.Errors {
+		toolErr.Fields = append(toolErr.Fields, ToolFieldError{
+			Field:   err.Field,
+			Type:    err.Type,
+			Message: err.Message,
+		})
+	}
+
+	return toolErr
+}
+
+// newToolResult wraps the handler's value
+func newToolResult(name string, value any) (*ToolResult, error) {
+	var content string
+
+	switch v := value.(type) {
+	case string:
+		content = v
+	case json.RawMessage:
+		content = string(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal the result of tool %s: %w", name, err)
+		}
+		content = string(data)
+	}
+
+	return &ToolResult{Tool: name, Content: content}, nil
+}
+
+// newToolErrorResult wraps the error of a failed call
+func newToolErrorResult(name string, toolErr *ToolError) *ToolResult {
+	// A ToolError only holds strings so it always marshals
+	data, _ := json.Marshal(toolErr)
+
+	return &ToolResult{
+		Tool:    name,
+		Content: string(data),
+		IsError: true,
+		Error:   toolErr,
+	}
+}
+
+// AnthropicToolResult is a tool_result content block of an Anthropic message
+type AnthropicToolResult struct {
+	Type      string `json:"type"`
+	ToolUseID string `json:"tool_use_id"`
+	Content   string `json:"content"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// OpenAIToolMessage is a message with the role tool of the OpenAI Chat
+// Completions API
+type OpenAIToolMessage struct {
+	Role       string `json:"role"`
+	ToolCallID string `json:"tool_call_id"`
+	Content    string `json:"content"`
+}
+
+// Anthropic renders the result as a tool_result content block
+func (r *ToolResult) Anthropic() *AnthropicToolResult {
+	return &AnthropicToolResult{
+		Type:      "tool_result",
+		ToolUseID: r.CallID,
+		Content:   r.Content,
+		IsError:   r.IsError,
+	}
+}
+
+// OpenAI renders the result as a tool message
+func (r *ToolResult) OpenAI() *OpenAIToolMessage {
+	return &OpenAIToolMessage{
+		Role:       "tool",
+		ToolCallID: r.CallID,
+		Content:    r.Content,
+	}
+}
@@ -0,0 +1,315 @@
+package gsv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	RepairWarning ValidationErrorType = "repair"
+)
+
+// RepairKind denotes the kind of damage ExtractJSON repaired
+type RepairKind string
+
+const (
+	// RepairCodeFence denotes the JSON was taken out of a markdown code fence
+	RepairCodeFence RepairKind = "code_fence"
+
+	// RepairSurroundingText denotes text before or after the JSON was removed
+	RepairSurroundingText RepairKind = "surrounding_text"
+
+	// RepairTrailingComma denotes a comma before a closing bracket was removed
+	RepairTrailingComma RepairKind = "trailing_comma"
+
+	// RepairSingleQuotes denotes a single quoted string was double quoted
+	RepairSingleQuotes RepairKind = "single_quotes"
+
+	// RepairUnquotedKey denotes an object key was quoted
+	RepairUnquotedKey RepairKind = "unquoted_key"
+
+	// RepairTruncated denotes unclosed strings, objects and arrays were
+	// closed at the end of the text
+	RepairTruncated RepairKind = "truncated"
+)
+
+// Repair is a fix ExtractJSON made to the text
+type Repair struct {
+	Kind RepairKind
+
+	// Offset is the byte offset of the damage in the original text
+	Offset int
+
+	// Message describes the fix
+	Message string
+}
+
+// warning converts the repair into a RepairWarning. The kind of the repair is
+// reported as the actual value.
+func (r Repair) warning() *ValidationError {
+	return &ValidationError{
+		Type:    RepairWarning,
+		Message: r.Message,
+		Actual:  r.Kind,
+	}
+}
+
+// codeFencePattern matches a markdown code fence, which may be missing its
+// closing backticks when the output was truncated
+var codeFencePattern = regexp.MustCompile("(?s)```[a-zA-Z0-9_-]*[ \t]*\r?\n?(.*?)(?:```|$)")
+
+// ExtractJSON finds the first JSON object or array in free text, like the
+// output of a model, and repairs common syntax damage: markdown code fences,
+// surrounding prose, trailing commas, single quoted strings, unquoted keys
+// and a truncated end. Every fix is returned as a Repair.
+//
+// Text that's valid JSON already is returned with its surrounding whitespace
+// trimmed and no repairs. An error is returned if no JSON object or array is
+// found or it can't be repaired.
+func ExtractJSON(text []byte) ([]byte, []Repair, error) {
+	if trimmed := bytes.TrimSpace(text); json.Valid(trimmed) {
+		return trimmed, nil, nil
+	}
+
+	r := &jsonRepairer{in: text}
+
+	// Prefer the first code fence holding a JSON value, models often put
+	// prose with braces around it
+	for _, match := range codeFencePattern.FindAllSubmatchIndex(text, -1) {
+		content := text[match[2]:match[3]]
+		if bytes.ContainsAny(content, "{[") {
+			r.add(RepairCodeFence, match[0], "extracted the JSON from a markdown code fence")
+			r.in = text[:match[3]]
+			r.pos = match[2]
+			break
+		}
+	}
+
+	if err := r.repair(); err != nil {
+		return nil, r.repairs, err
+	}
+
+	if err := json.Unmarshal(r.out, new(interface{})); err != nil {
+		return nil, r.repairs, fmt.Errorf("could not repair json: %w", err)
+	}
+
+	return r.out, r.repairs, nil
+}
+
+// jsonRepairer rewrites the JSON value starting at pos into out
+type jsonRepairer struct {
+	in  []byte
+	pos int
+	out []byte
+
+	// stack holds the opening bracket of every open object and array
+	stack []byte
+
+	// expectKey denotes the next token is an object key and keyOpen that a
+	// key was read but not its colon yet
+	expectKey bool
+	keyOpen   bool
+
+	// comma is the index in out of a comma that was only followed by
+	// whitespace, -1 if there's none
+	comma int
+
+	repairs []Repair
+}
+
+// add records a repair at the offset of the input
+func (r *jsonRepairer) add(kind RepairKind, offset int, message string) {
+	r.repairs = append(r.repairs, Repair{Kind: kind, Offset: offset, Message: message})
+}
+
+// repair finds the start of the value and rewrites it
+func (r *jsonRepairer) repair() error {
+	start := bytes.IndexAny(r.in[r.pos:], "{[")
+	if start < 0 {
+		return errors.New("no JSON object or array found")
+	}
+	start += r.pos
+
+	if len(bytes.TrimSpace(r.in[r.pos:start])) > 0 {
+		r.add(RepairSurroundingText, r.pos, "removed the text before the JSON")
+	}
+
+	r.pos = start
+	r.comma = -1
+
+	for r.pos < len(r.in) {
+		c := r.in[r.pos]
+
+		switch {
+		case isJSONSpace(c):
+			r.out = append(r.out, c)
+			r.pos++
+
+		case c == '{' || c == '[':
+			r.write(c)
+			r.stack = append(r.stack, c)
+			r.expectKey = c == '{'
+			r.pos++
+
+		case c == '}' || c == ']':
+			if r.comma >= 0 {
+				r.add(RepairTrailingComma, r.pos, "removed a trailing comma")
+				r.out = append(r.out[:r.comma], r.out[r.comma+1:]...)
+				r.comma = -1
+			}
+
+			r.write(c)
+			r.stack = r.stack[:len(r.stack)-1]
+			r.expectKey = false
+			r.pos++
+
+			if len(r.stack) == 0 {
+				if len(bytes.TrimSpace(r.in[r.pos:])) > 0 {
+					r.add(RepairSurroundingText, r.pos, "removed the text after the JSON")
+				}
+				return nil
+			}
+
+		case c == ',':
+			r.write(c)
+			r.comma = len(r.out) - 1
+			r.expectKey = r.stack[len(r.stack)-1] == '{'
+			r.pos++
+
+		case c == ':':
+			r.write(c)
+			r.expectKey = false
+			r.keyOpen = false
+			r.pos++
+
+		case c == '"':
+			r.readString('"')
+
+		case c == '\'':
+			r.add(RepairSingleQuotes, r.pos, "replaced single quotes with double quotes")
+			r.readString('\'')
+
+		case r.expectKey && isIdentStart(c):
+			start := r.pos
+			for r.pos < len(r.in) && isIdentPart(r.in[r.pos]) {
+				r.pos++
+			}
+
+			r.add(RepairUnquotedKey, start, fmt.Sprintf("quoted the key %s", r.in[start:r.pos]))
+			r.write('"')
+			r.out = append(r.out, r.in[start:r.pos]...)
+			r.out = append(r.out, '"')
+			r.expectKey = false
+			r.keyOpen = true
+
+		default:
+			// Numbers and literals are copied as is, anything invalid is
+			// reported when the result is decoded
+			r.write(c)
+			r.pos++
+		}
+	}
+
+	r.closeTruncated()
+
+	return nil
+}
+
+// write appends a token character to out
+func (r *jsonRepairer) write(c byte) {
+	r.out = append(r.out, c)
+	r.comma = -1
+}
+
+// readString rewrites the string starting at pos, which is quoted with
+// quote, as a double quoted string. A string that isn't closed before the
+// end of the input is closed.
+func (r *jsonRepairer) readString(quote byte) {
+	r.keyOpen = r.expectKey
+	r.expectKey = false
+
+	r.write('"')
+	r.pos++
+
+	for r.pos < len(r.in) {
+		c := r.in[r.pos]
+
+		switch {
+		case c == quote:
+			r.out = append(r.out, '"')
+			r.pos++
+			return
+
+		case c == '\\' && r.pos+1 < len(r.in):
+			next := r.in[r.pos+1]
+			if quote == '\'' && next == '\'' {
+				// \' isn't a valid JSON escape
+				r.out = append(r.out, '\'')
+			} else {
+				r.out = append(r.out, c, next)
+			}
+			r.pos += 2
+
+		case c == '\\':
+			// A lone backslash at the end of truncated input
+			r.pos++
+
+		case c == '"':
+			// Only reached for single quoted strings
+			r.out = append(r.out, '\\', '"')
+			r.pos++
+
+		default:
+			r.out = append(r.out, c)
+			r.pos++
+		}
+	}
+
+	r.add(RepairTruncated, r.pos, "closed an unterminated string")
+	r.out = append(r.out, '"')
+}
+
+// closeTruncated closes every object and array left open at the end of the
+// input
+func (r *jsonRepairer) closeTruncated() {
+	if len(r.stack) == 0 {
+		return
+	}
+
+	// Drop a dangling comma and give a dangling key a value
+	r.out = bytes.TrimRight(r.out, " \t\r\n")
+	switch {
+	case r.keyOpen:
+		r.out = append(r.out, ":null"...)
+	case bytes.HasSuffix(r.out, []byte(",")):
+		r.out = r.out[:len(r.out)-1]
+	case bytes.HasSuffix(r.out, []byte(":")):
+		r.out = append(r.out, "null"...)
+	}
+
+	r.add(RepairTruncated, len(r.in), fmt.Sprintf("closed %d unclosed objects and arrays", len(r.stack)))
+
+	for i := len(r.stack) - 1; i >= 0; i-- {
+		if r.stack[i] == '{' {
+			r.out = append(r.out, '}')
+		} else {
+			r.out = append(r.out, ']')
+		}
+	}
+	r.stack = nil
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c == '-' || (c >= '0' && c <= '9')
+}
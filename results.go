@@ -8,6 +8,10 @@ import (
 // ValidationResult holds all validation errors for a schema
 type ValidationResult struct {
 	Errors []*ValidationError
+
+	// Warnings are the issues that didn't fail validation, like values that
+	// were coerced into the type of their schema
+	Warnings []*ValidationError
 }
 
 // Helper methods for ValidationResult
@@ -19,6 +23,24 @@ func (vr *ValidationResult) AddError(err *ValidationError) {
 	vr.Errors = append(vr.Errors, err)
 }
 
+// HasWarnings reports whether any warnings were collected
+func (vr *ValidationResult) HasWarnings() bool {
+	return len(vr.Warnings) > 0
+}
+
+func (vr *ValidationResult) AddWarning(warning *ValidationError) {
+	vr.Warnings = append(vr.Warnings, warning)
+}
+
+// addWarnings adds the warnings of a nested schema, prefixing their paths
+// with the path of the nested schema
+func (vr *ValidationResult) addWarnings(warnings []*ValidationError, path ...interface{}) {
+	for _, warning := range warnings {
+		warning.prependPath(path...)
+		vr.AddWarning(warning)
+	}
+}
+
 // Error converts the ValidationResult into a single error message
 // This implements the error interface and provides a clean way to convert
 // structured errors into a single error when needed
@@ -30,9 +52,9 @@ func (vr *ValidationResult) Error() error {
 	var errMsgs []string
 	for _, err := range vr.Errors {
 		// Include field path if it exists
-		if err.Field != "" {
+		if path := err.DottedPath(); path != "" {
 			errMsgs = append(errMsgs, fmt.Sprintf("%s: [%s] %s",
-				err.Field,
+				path,
 				err.Type,
 				err.Message))
 		} else {
@@ -0,0 +1,139 @@
+package gsv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMaxAttempts is returned by ParseWithRetry when no attempt produced a
+// valid result
+var ErrMaxAttempts = errors.New("max attempts reached")
+
+// GenerateFunc generates the JSON output of a model. feedback is empty for
+// the first attempt and holds the corrective prompt for the errors of the
+// previous attempt otherwise.
+type GenerateFunc func(ctx context.Context, feedback string) ([]byte, error)
+
+// Attempt is a single generation of ParseWithRetry
+type Attempt struct {
+	// Feedback is the feedback the output was generated with
+	Feedback string
+
+	// Output is the generated JSON
+	Output []byte
+
+	// Result is the result of parsing the output, nil if it couldn't be
+	// decoded
+	Result *ValidationResult
+
+	// Err is the error of decoding the output
+	Err error
+}
+
+// Valid reports whether the output was decoded and passed validation
+func (a *Attempt) Valid() bool {
+	return a.Err == nil && a.Result != nil && !a.Result.HasErrors()
+}
+
+// Correction renders the errors of the attempt into a prompt asking the model
+// to fix them. It's empty for valid attempts.
+func (a *Attempt) Correction() string {
+	if a.Valid() {
+		return ""
+	}
+
+	if a.Err != nil {
+		return fmt.Sprintf("Your previous answer couldn't be parsed: %s\n"+
+			"Answer again with only the JSON object.", a.Err)
+	}
+
+	var b strings.Builder
+	b.WriteString("Your previous answer had these errors:\n")
+	for _, err := range a.Result.Errors {
+		writeCorrection(&b, err, "")
+	}
+	b.WriteString("Fix them and answer again with the whole JSON object.")
+
+	return b.String()
+}
+
+// writeCorrection writes the error as a list item, followed by its causes
+// indented below it
+func writeCorrection(b *strings.Builder, err *ValidationError, indent string) {
+	field := err.Field
+	if field == "" {
+		field = "the root value"
+	}
+
+	fmt.Fprintf(b, "%s- %s: %s\n", indent, field, err.Message)
+	for _, cause := range err.Causes {
+		writeCorrection(b, cause, indent+"  ")
+	}
+}
+
+// ParseWithRetry generates the output with generate and parses it into t,
+// retrying up to maxAttempts times. Every retry is generated with the
+// Correction of the previous attempt as feedback. It stops at the first
+// valid output, which is left in t, and returns every attempt made.
+//
+// ErrMaxAttempts is returned, along with the error of the last attempt, when
+// no output was valid. Errors of generate and of the context are returned as
+// is. It panics if maxAttempts is less than 1.
+//
+// With the PanicOnError mode the attempts are still retried: it only panics
+// when the last attempt fails validation.
+func ParseWithRetry[T any](ctx context.Context, generate GenerateFunc, t *T, maxAttempts int, opts ...ParseOptions) ([]*Attempt, error) {
+	if maxAttempts < 1 {
+		panic(fmt.Sprintf("invalid max attempts: %d", maxAttempts))
+	}
+
+	options := resolveParseOptions(opts)
+	panicOnError := options.ErrorMode == PanicOnError
+	if panicOnError {
+		options.ErrorMode = ReturnAllErrors
+	}
+
+	var (
+		attempts []*Attempt
+		feedback string
+	)
+
+	for len(attempts) < maxAttempts {
+		if err := ctx.Err(); err != nil {
+			return attempts, err
+		}
+
+		output, err := generate(ctx, feedback)
+		if err != nil {
+			return attempts, fmt.Errorf("could not generate attempt %d: %w", len(attempts)+1, err)
+		}
+
+		attempt := &Attempt{Feedback: feedback, Output: output}
+		attempts = append(attempts, attempt)
+
+		// Each attempt is parsed into a fresh copy so fields missing from
+		// the output aren't filled in by earlier attempts
+		parsed := cloneStruct(t)
+		attempt.Result, attempt.Err = Parse(output, parsed, options)
+
+		if attempt.Valid() {
+			*t = *parsed
+			return attempts, nil
+		}
+
+		feedback = attempt.Correction()
+	}
+
+	last := attempts[len(attempts)-1]
+	if last.Err != nil {
+		return attempts, fmt.Errorf("%w (%d): %w", ErrMaxAttempts, maxAttempts, last.Err)
+	}
+
+	if panicOnError {
+		panic(last.Result.Error())
+	}
+
+	return attempts, fmt.Errorf("%w (%d): %w", ErrMaxAttempts, maxAttempts, last.Result.Error())
+}
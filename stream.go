@@ -0,0 +1,402 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// FieldState denotes how much of a field a StreamParser has received
+type FieldState int
+
+const (
+	// FieldAbsent denotes the field's key hasn't been received
+	FieldAbsent FieldState = iota
+
+	// FieldInProgress denotes the field's key was received but its value
+	// isn't complete yet
+	FieldInProgress
+
+	// FieldComplete denotes the field's value was received in full
+	FieldComplete
+)
+
+// String implements fmt.Stringer
+func (s FieldState) String() string {
+	switch s {
+	case FieldInProgress:
+		return "in progress"
+	case FieldComplete:
+		return "complete"
+	default:
+		return "absent"
+	}
+}
+
+// StreamParser parses JSON that arrives in chunks, like the tokens streamed
+// by a model, into a gsv schema struct. After every chunk the struct holds a
+// best-effort value of the JSON received so far: unfinished strings,
+// numbers, objects and arrays are closed where they end and members that
+// can't be closed yet, like a key without its value, are left out.
+//
+// Validators only run on complete fields while streaming. Close validates
+// the whole document like Parse.
+type StreamParser[T any] struct {
+	schema   *T
+	template *T
+	opts     ParseOptions
+
+	buf    []byte
+	states map[string]FieldState
+	result *ValidationResult
+}
+
+// NewStreamParser creates a parser that streams into the schema struct t,
+// honoring the first ParseOptions given
+func NewStreamParser[T any](t *T, opts ...ParseOptions) *StreamParser[T] {
+	return &StreamParser[T]{
+		schema:   t,
+		template: cloneStruct(t),
+		opts:     resolveParseOptions(opts),
+		states:   make(map[string]FieldState),
+		result:   &ValidationResult{},
+	}
+}
+
+// Write adds a chunk of the stream and updates the schema struct. It
+// implements io.Writer. An error is returned if the JSON received so far is
+// malformed or has a value of the wrong type.
+//
+// Every chunk rescans and decodes the whole document received so far, so
+// streaming a document costs time quadratic in its number of chunks. That's
+// cheap for the size of model outputs; buffer larger documents into fewer
+// chunks.
+func (p *StreamParser[T]) Write(chunk []byte) (int, error) {
+	p.buf = append(p.buf, chunk...)
+
+	s := &partialScanner{in: p.buf, states: make(map[string]FieldState)}
+	if err := s.scan(); err != nil {
+		return len(chunk), err
+	}
+
+	if len(s.out) == 0 {
+		return len(chunk), nil
+	}
+
+	// Every update decodes into a fresh copy, the closed values of the last
+	// update don't necessarily match the ones of the next
+	partial := cloneStruct(p.template)
+	if err := decodeValue(reflect.ValueOf(partial), s.out, nil, &p.opts); err != nil {
+		return len(chunk), fmt.Errorf("could not unmarshal json: %w", err)
+	}
+
+	*p.schema = *partial
+	p.states = s.states
+
+	validated := ensureRecursive(reflect.ValueOf(p.schema), nil, &p.opts)
+	p.result = &ValidationResult{
+		Errors:   p.completed(validated.Errors),
+		Warnings: p.completed(validated.Warnings),
+	}
+
+	return len(chunk), nil
+}
+
+// completed returns the errors of fields that are complete or nested in a
+// complete field
+func (p *StreamParser[T]) completed(errs []*ValidationError) []*ValidationError {
+	var kept []*ValidationError
+
+	for _, err := range errs {
+		for i := len(err.Path); i > 0; i-- {
+			if p.states[jsonPointer(err.Path[:i])] == FieldComplete {
+				kept = append(kept, err)
+				break
+			}
+		}
+	}
+
+	return kept
+}
+
+// State returns the state of the field at the JSON pointer, e.g. "/title" or
+// "/items/0/name"
+func (p *StreamParser[T]) State(pointer string) FieldState {
+	return p.states[pointer]
+}
+
+// Result returns the validation result of the complete fields as of the last
+// chunk
+func (p *StreamParser[T]) Result() *ValidationResult {
+	return p.result
+}
+
+// Close ends the stream and parses the whole document into the schema struct
+// like Parse, validating every field
+func (p *StreamParser[T]) Close() (*ValidationResult, error) {
+	parsed := cloneStruct(p.template)
+
+	result, err := Parse(p.buf, parsed, p.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	*p.schema = *parsed
+	p.result = result
+
+	return result, nil
+}
+
+// partialScanner rewrites the prefix of a JSON document into a complete
+// document, recording the state of every object member and array element
+type partialScanner struct {
+	in  []byte
+	pos int
+	out []byte
+
+	states map[string]FieldState
+}
+
+// scan rewrites the root value
+func (s *partialScanner) scan() error {
+	_, _, err := s.value(nil)
+	return err
+}
+
+// value rewrites the value at pos. usable reports whether anything was
+// written and complete whether the value ended before the input did.
+func (s *partialScanner) value(path []interface{}) (complete, usable bool, err error) {
+	s.skipSpace()
+	if s.pos == len(s.in) {
+		return false, false, nil
+	}
+
+	switch c := s.in[s.pos]; {
+	case c == '{':
+		return s.container(path, '}')
+	case c == '[':
+		return s.container(path, ']')
+	case c == '"':
+		complete, _ = s.string()
+		return complete, true, nil
+	default:
+		return s.literal()
+	}
+}
+
+// container rewrites the object or array at pos, closing it if the input
+// ends inside it
+func (s *partialScanner) container(path []interface{}, end byte) (complete, usable bool, err error) {
+	s.out = append(s.out, s.in[s.pos])
+	s.pos++
+
+	// comma denotes a comma was read after the last member, which must be
+	// followed by another member
+	for members, comma := 0, false; ; {
+		s.skipSpace()
+		if s.pos == len(s.in) {
+			s.out = append(s.out, end)
+			return false, true, nil
+		}
+
+		switch c := s.in[s.pos]; {
+		case c == end && !comma:
+			s.out = append(s.out, end)
+			s.pos++
+			return true, true, nil
+
+		case c == ',' && members > 0 && !comma:
+			comma = true
+			s.pos++
+			continue
+
+		case c == end || c == ',' || (members > 0 && !comma):
+			return false, false, s.unexpected()
+		}
+		comma = false
+
+		// Members are written with their comma so they can be dropped as a
+		// whole if they can't be closed
+		mark := len(s.out)
+		if members > 0 {
+			s.out = append(s.out, ',')
+		}
+
+		var memberPath []interface{}
+		if end == '}' {
+			if s.in[s.pos] != '"' {
+				return false, false, s.unexpected()
+			}
+
+			keyStart := len(s.out)
+			if closed, _ := s.string(); !closed {
+				s.out = append(s.out[:mark], end)
+				return false, true, nil
+			}
+
+			var key string
+			if err := json.Unmarshal(s.out[keyStart:], &key); err != nil {
+				return false, false, err
+			}
+			memberPath = appendPath(path, key)
+
+			s.skipSpace()
+			if s.pos == len(s.in) {
+				s.out = append(s.out[:mark], end)
+				return false, true, nil
+			}
+			if s.in[s.pos] != ':' {
+				return false, false, s.unexpected()
+			}
+			s.out = append(s.out, ':')
+			s.pos++
+		} else {
+			memberPath = appendPath(path, members)
+		}
+
+		pointer := jsonPointer(memberPath)
+		s.states[pointer] = FieldInProgress
+
+		complete, usable, err := s.value(memberPath)
+		if err != nil {
+			return false, false, err
+		}
+
+		if !usable {
+			s.out = s.out[:mark]
+		} else {
+			members++
+		}
+
+		if !complete {
+			s.out = append(s.out, end)
+			return false, true, nil
+		}
+		s.states[pointer] = FieldComplete
+	}
+}
+
+// string copies the string at pos, closing it if the input ends inside it.
+// An escape sequence or UTF-8 character cut off by the end is dropped.
+func (s *partialScanner) string() (closed bool, err error) {
+	start := s.pos
+	s.pos++
+
+	for s.pos < len(s.in) {
+		switch s.in[s.pos] {
+		case '"':
+			s.pos++
+			s.out = append(s.out, s.in[start:s.pos]...)
+			return true, nil
+
+		case '\\':
+			s.pos += 2
+
+		default:
+			s.pos++
+		}
+	}
+
+	s.pos = len(s.in)
+	partial := s.in[start:]
+
+	// Drop a cut off escape sequence, \uXXXX being the longest
+	for i := len(partial) - 1; i >= 0 && i >= len(partial)-6; i-- {
+		if partial[i] == '\\' && !escaped(partial, i) {
+			if !completeEscape(partial[i:]) {
+				partial = partial[:i]
+			}
+			break
+		}
+	}
+
+	// Drop a cut off UTF-8 character
+	for len(partial) > 0 && !utf8.FullRune(partial[lastRuneStart(partial):]) {
+		partial = partial[:lastRuneStart(partial)]
+	}
+
+	s.out = append(s.out, partial...)
+	s.out = append(s.out, '"')
+
+	return false, nil
+}
+
+// literal copies the number, boolean or null at pos. A number cut off by the
+// end is used as far as it's valid and marked incomplete, other cut off
+// literals are dropped.
+func (s *partialScanner) literal() (complete, usable bool, err error) {
+	start := s.pos
+	for s.pos < len(s.in) && !isJSONSpace(s.in[s.pos]) && !isJSONDelimiter(s.in[s.pos]) {
+		s.pos++
+	}
+	token := s.in[start:s.pos]
+
+	if s.pos < len(s.in) {
+		s.out = append(s.out, token...)
+		return true, true, nil
+	}
+
+	switch string(token) {
+	case "true", "false", "null":
+		s.out = append(s.out, token...)
+		return true, true, nil
+	}
+
+	if token[0] == '-' || (token[0] >= '0' && token[0] <= '9') {
+		if json.Valid(token) {
+			s.out = append(s.out, token...)
+			return false, true, nil
+		}
+	}
+
+	return false, false, nil
+}
+
+// skipSpace advances pos past whitespace
+func (s *partialScanner) skipSpace() {
+	for s.pos < len(s.in) && isJSONSpace(s.in[s.pos]) {
+		s.pos++
+	}
+}
+
+// unexpected returns the error for the character at pos
+func (s *partialScanner) unexpected() error {
+	return fmt.Errorf("invalid character %q at offset %d", s.in[s.pos], s.pos)
+}
+
+func isJSONDelimiter(c byte) bool {
+	return c == ',' || c == ':' || c == '}' || c == ']' || c == '{' || c == '[' || c == '"'
+}
+
+// escaped reports whether the byte at i is escaped by the backslashes before
+// it
+func escaped(data []byte, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && data[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// completeEscape reports whether the escape sequence at the start of data is
+// complete
+func completeEscape(data []byte) bool {
+	if len(data) < 2 {
+		return false
+	}
+	if data[1] == 'u' {
+		return len(data) >= 6
+	}
+	return true
+}
+
+// lastRuneStart returns the index of the first byte of the last UTF-8
+// character in data
+func lastRuneStart(data []byte) int {
+	i := len(data) - 1
+	for i > 0 && !utf8.RuneStart(data[i]) {
+		i--
+	}
+	return i
+}
@@ -3,12 +3,14 @@ package gsv
 import (
 	"encoding/json"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/agent-api/gsv/pkg/jsonschema"
 )
 
-// stringValidatorFunc is a validation function that expects a string:
-type stringValidatorFunc func(string)
+// stringValidatorFunc is a validation function that expects a string and
+// returns a ValidationError if the string is invalid
+type stringValidatorFunc func(string) *ValidationError
 
 const (
 	MinStringLengthError   ValidationErrorType = "min_string_length"
@@ -21,6 +23,28 @@ const (
 	StringSchemaType string = "string"
 )
 
+// stringLengthMode denotes how the length of a string is counted
+type stringLengthMode int
+
+const (
+	// runeLength counts Unicode code points
+	runeLength stringLengthMode = iota
+
+	// byteLength counts UTF-8 encoded bytes
+	byteLength
+
+	// graphemeLength counts grapheme clusters
+	graphemeLength
+)
+
+// unit returns the name of what's counted, for error messages
+func (m stringLengthMode) unit() string {
+	if m == byteLength {
+		return "bytes"
+	}
+	return "characters"
+}
+
 // StringSchema implements the Schema interface for strings.
 type StringSchema struct {
 	schemaType string
@@ -31,12 +55,41 @@ type StringSchema struct {
 	// maxLength is the optional field to denote the maximum length of the string
 	maxLength *int
 
+	// minMessage and maxMessage override the messages of the length errors
+	minMessage string
+	maxMessage string
+
+	// lengthMode denotes how the length of the string is counted
+	lengthMode stringLengthMode
+
+	// patterns are the regular expressions the string must match, compiled to
+	// the "pattern" keyword
+	patterns []string
+
+	// formats are the JSON schema formats of the string, like "email"
+	formats []string
+
 	// validators are the registered functions to validate the string against
 	validators []stringValidatorFunc
 
+	// preprocessors and transforms normalize the string while it's decoded
+	preprocessors []preprocessFunc
+	transforms    []func(string) (string, error)
+
+	// transformErr is the error of the last failed transform
+	transformErr error
+
 	// value is the actual string value
 	value *string
 
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[string]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
+
 	description *string
 
 	// isOptional denotes if the string value in the schema is optional
@@ -61,6 +114,39 @@ func (s *StringSchema) Description(val string) *StringSchema {
 	return s
 }
 
+// Nullable marks JSON null as a valid value of the string. Unlike Optional,
+// the key must still be present unless the string is optional as well.
+func (s *StringSchema) Nullable() *StringSchema {
+	s.isNullable = true
+	return s
+}
+
+// IsNullable implements Schema.IsNullable
+func (s *StringSchema) IsNullable() bool {
+	return s.isNullable
+}
+
+// State implements Schema.State
+func (s *StringSchema) State() ValueState {
+	_, ok := s.getValue()
+	return valueState(s.isNull, ok)
+}
+
+// Default marks the string field as optional and sets the value it takes when
+// it's missing
+func (s *StringSchema) Default(v string) *StringSchema {
+	s.defaultValue = newDefault(v)
+	s.isOptional = true
+	return s
+}
+
+// DefaultFunc is Default with a value built by fn for every missing string
+func (s *StringSchema) DefaultFunc(fn func() string) *StringSchema {
+	s.defaultValue = newDefaultFunc(fn)
+	s.isOptional = true
+	return s
+}
+
 // Optional marks the string field as optional
 func (s *StringSchema) Optional() *StringSchema {
 	s.isOptional = true
@@ -72,88 +158,195 @@ func (s *StringSchema) IsOptional() bool {
 	return s.isOptional
 }
 
-// Min adds minimum length validation
+// Min adds minimum length validation. The length is counted in runes unless
+// Bytes or Graphemes is used.
 func (v *StringSchema) Min(length int, opts ...ValidationOptions) *StringSchema {
 	v.minLength = &length
-
-	validationMessage := fmt.Sprintf("must be at least %d characters long", *v.minLength)
-	if len(opts) > 0 && opts[0].Message != "" {
-		validationMessage = opts[0].Message
+	v.minMessage = ""
+	if len(opts) > 0 {
+		v.minMessage = opts[0].Message
 	}
 
-	v.validators = append(v.validators, func(s string) {
-		if *v.minLength > len(s) {
-			v.result.AddError(&ValidationError{
-				Type:     MinStringLengthError,
-				Message:  validationMessage,
-				Expected: *v.minLength,
-				Actual:   len(s),
-			})
-		}
-	})
-
 	return v
 }
 
-// Max adds maximum length validation
+// Max adds maximum length validation. The length is counted in runes unless
+// Bytes or Graphemes is used.
 func (v *StringSchema) Max(length int, opts ...ValidationOptions) *StringSchema {
 	v.maxLength = &length
+	v.maxMessage = ""
+	if len(opts) > 0 {
+		v.maxMessage = opts[0].Message
+	}
+
+	return v
+}
+
+// Runes counts the length of the string in Unicode code points, the way JSON
+// Schema's minLength and maxLength do. This is the default.
+func (v *StringSchema) Runes() *StringSchema {
+	v.lengthMode = runeLength
+	return v
+}
 
-	validationMessage := fmt.Sprintf("must be at most %d characters long", *v.maxLength)
-	if len(opts) > 0 && opts[0].Message != "" {
-		validationMessage = opts[0].Message
+// Bytes counts the length of the string in UTF-8 encoded bytes, e.g. to fit a
+// database column
+func (v *StringSchema) Bytes() *StringSchema {
+	v.lengthMode = byteLength
+	return v
+}
+
+// Graphemes counts the length of the string in grapheme clusters, the
+// characters a user perceives. An emoji like 👍🏽 counts as one.
+func (v *StringSchema) Graphemes() *StringSchema {
+	v.lengthMode = graphemeLength
+	return v
+}
+
+// length returns the length of s as counted by the schema's length mode
+func (v *StringSchema) length(s string) int {
+	switch v.lengthMode {
+	case byteLength:
+		return len(s)
+	case graphemeLength:
+		return graphemeCount(s)
+	default:
+		return utf8.RuneCountInString(s)
 	}
+}
 
-	v.validators = append(v.validators, func(s string) {
-		if *v.maxLength < len(s) {
-			v.result.AddError(&ValidationError{
-				Type:     MaxStringLengthError,
-				Message:  validationMessage,
-				Expected: *v.maxLength,
-				Actual:   len(s),
-			})
+// validateLength checks the length of s against the minimum and maximum length
+func (v *StringSchema) validateLength(s string, opts *ParseOptions) {
+	if v.minLength == nil && v.maxLength == nil {
+		return
+	}
+
+	length := v.length(s)
+
+	if v.minLength != nil && *v.minLength > length {
+		v.result.AddError(&ValidationError{
+			Type:     MinStringLengthError,
+			Message:  messageOr(v.minMessage, fmt.Sprintf("must be at least %d %s long", *v.minLength, v.lengthMode.unit())),
+			Expected: *v.minLength,
+			Actual:   length,
+		})
+
+		if opts.StopOnFirst {
+			return
 		}
-	})
+	}
 
+	if v.maxLength != nil && *v.maxLength < length {
+		v.result.AddError(&ValidationError{
+			Type:     MaxStringLengthError,
+			Message:  messageOr(v.maxMessage, fmt.Sprintf("must be at most %d %s long", *v.maxLength, v.lengthMode.unit())),
+			Expected: *v.maxLength,
+			Actual:   length,
+		})
+	}
+}
+
+// Refine adds a custom validation. The check returns false for invalid
+// strings, which reports a CustomError with the message from the options.
+func (v *StringSchema) Refine(check func(string) bool, opts ...ValidationOptions) *StringSchema {
+	v.validators = append(v.validators, stringValidatorFunc(newRefinement(check, opts)))
+	return v
+}
+
+// Preprocess adds a function that rewrites the decoded JSON value before it's
+// decoded as a string, e.g. to turn numbers into strings
+func (v *StringSchema) Preprocess(fn func(interface{}) interface{}) *StringSchema {
+	v.preprocessors = append(v.preprocessors, fn)
+	return v
+}
+
+// Transform adds a function that normalizes the string while it's decoded,
+// e.g. strings.TrimSpace, so the validators see the normalized string. An
+// error is reported as a TransformError.
+func (v *StringSchema) Transform(fn func(string) (string, error)) *StringSchema {
+	v.transforms = append(v.transforms, fn)
 	return v
 }
 
 // Validate performs the validation
 func (v *StringSchema) Validate() *ValidationResult {
+	return v.validate(&ParseOptions{})
+}
+
+func (v *StringSchema) validate(opts *ParseOptions) *ValidationResult {
 	v.result = &ValidationResult{}
 
+	if v.isNull {
+		if !v.isNullable {
+			v.result.AddError(newNullError())
+		}
+		return v.result
+	}
+
 	val, ok := v.Value()
-	if !ok && !v.isOptional {
-		v.result.AddError(&ValidationError{
-			Type:    RequiredStringError,
-			Message: "value has not been set",
-		})
+	if !ok {
+		if !v.isOptional {
+			v.result.AddError(&ValidationError{
+				Type:    RequiredStringError,
+				Message: "value has not been set",
+			})
+		}
+		return v.result
+	}
+
+	if v.transformErr != nil {
+		v.result.AddError(newTransformError(v.transformErr, val))
+		return v.result
+	}
+
+	v.validateLength(val, opts)
+	if opts.StopOnFirst && v.result.HasErrors() {
 		return v.result
 	}
 
 	for _, validator := range v.validators {
-		validator(val)
+		if err := validator(val); err != nil {
+			v.result.AddError(err)
+
+			if opts.StopOnFirst {
+				break
+			}
+		}
 	}
 
 	return v.result
 }
 
 func (v *StringSchema) Set(s string) *StringSchema {
+	v.isNull = false
 	v.value = &s
+	v.transformErr = nil
 	return v
 }
 
 func (v *StringSchema) setValue(val interface{}) error {
+	if val == nil && v.isNullable {
+		v.value = nil
+		v.isNull = true
+		return nil
+	}
+	v.isNull = false
+
 	s, ok := val.(string)
 	if !ok {
 		return fmt.Errorf("expected string value, got %T", val)
 	}
 	v.value = &s
+	v.transformErr = nil
 	return nil
 }
 
 // UnmarshalJSON implements json.Unmarshaler
 func (s *StringSchema) MarshalJSON() ([]byte, error) {
+	if s.isNull {
+		return json.Marshal(nil)
+	}
+
 	if s.value == nil {
 		if s.isOptional {
 			return json.Marshal(nil)
@@ -166,22 +359,33 @@ func (s *StringSchema) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements json.Unmarshaler
 func (s *StringSchema) UnmarshalJSON(data []byte) error {
+	if err := s.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
 
-	// Handle null values
-	if string(data) == "null" {
-		if !s.isOptional {
-			return fmt.Errorf("validation failed: field is required")
-		}
-		s.value = nil
-		return nil
+	// Run validation and return errors if there are any
+	if result := s.Validate(); result.HasErrors() {
+		return result.Error()
 	}
 
-	// Handle missing fields (empty string in JSON)
-	if len(data) == 0 {
-		if !s.isOptional {
-			return fmt.Errorf("validation failed: field is required")
-		}
+	return nil
+}
+
+func (s *StringSchema) decode(data []byte, _ *ParseOptions) error {
+	data, err := preprocess(data, s.preprocessors)
+	if err != nil {
+		return fmt.Errorf("invalid string value: %w", err)
+	}
+
+	s.transformErr = nil
+
+	s.isNull = string(data) == "null"
+
+	// Handle null values and missing fields (empty string in JSON). Whether
+	// the value is required is left to the validators
+	if len(data) == 0 || string(data) == "null" {
 		s.value = nil
+		applyDefault(s, s.defaultValue)
 		return nil
 	}
 
@@ -191,14 +395,16 @@ func (s *StringSchema) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("invalid string value: %w", err)
 	}
 
+	// Normalize the value, keeping the original one if a transform fails
+	if transformed, err := transformValue(str, s.transforms); err != nil {
+		s.transformErr = err
+	} else {
+		str = transformed
+	}
+
 	// Store the value
 	s.value = &str
 
-	// Run validation and return errors if there are any
-	if result := s.Validate(); result.HasErrors() {
-		return result.Error()
-	}
-
 	return nil
 }
 
@@ -208,7 +414,7 @@ func (s *StringSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag
 	}
 
 	propertySchema := &jsonschema.JSONSchema{
-		Type: "string",
+		Type: jsonSchemaTypes(StringSchemaType, s.isNullable),
 	}
 
 	// Add description if present
@@ -216,14 +422,27 @@ func (s *StringSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag
 		propertySchema.Description = *s.description
 	}
 
-	// Add min length if present
-	if s.minLength != nil {
-		propertySchema.MinLength = s.minLength
-	}
+	propertySchema.Default = s.defaultValue.jsonSchemaDefault()
 
-	// Add max length if present
-	if s.maxLength != nil {
-		propertySchema.MaxLength = s.maxLength
+	// Add the min and max length. JSON schema counts runes, so for the other
+	// length modes only the bounds they imply on the number of runes are added
+	propertySchema.MinLength, propertySchema.MaxLength = s.jsonSchemaLength()
+
+	// Add the patterns and formats. A schema holds a single pattern and format
+	// so any others are added through allOf
+	for i, pattern := range s.patterns {
+		if i == 0 {
+			propertySchema.Pattern = pattern
+			continue
+		}
+		propertySchema.AllOf = append(propertySchema.AllOf, &jsonschema.JSONSchema{Pattern: pattern})
+	}
+	for i, format := range s.formats {
+		if i == 0 {
+			propertySchema.Format = format
+			continue
+		}
+		propertySchema.AllOf = append(propertySchema.AllOf, &jsonschema.JSONSchema{Format: format})
 	}
 
 	// Add to required fields if not optional
@@ -235,17 +454,50 @@ func (s *StringSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag
 	return nil
 }
 
+// jsonSchemaLength returns the minLength and maxLength keywords matching the
+// length validation. A string of n bytes has at most n and at least n/4
+// runes, and a string of n graphemes has at least n runes but any number more.
+func (s *StringSchema) jsonSchemaLength() (minLength, maxLength *int) {
+	switch s.lengthMode {
+	case byteLength:
+		if s.minLength != nil {
+			min := (*s.minLength + utf8.UTFMax - 1) / utf8.UTFMax
+			minLength = &min
+		}
+		return minLength, s.maxLength
+
+	case graphemeLength:
+		return s.minLength, nil
+
+	default:
+		return s.minLength, s.maxLength
+	}
+}
+
 // Clone implements Schema.Clone by creating a deep copy of the StringSchema
 func (s *StringSchema) Clone() Schema {
 	// Create new instance
 	clone := &StringSchema{
 		schemaType: s.schemaType,
+		minMessage: s.minMessage,
+		maxMessage: s.maxMessage,
+		lengthMode: s.lengthMode,
 		isOptional: s.isOptional,
+		patterns:   make([]string, len(s.patterns)),
+		formats:    make([]string, len(s.formats)),
 		validators: make([]stringValidatorFunc, len(s.validators)),
+
+		preprocessors: make([]preprocessFunc, len(s.preprocessors)),
+		transforms:    make([]func(string) (string, error), len(s.transforms)),
+		transformErr:  s.transformErr,
 	}
 
-	// Deep copy the validators slice
+	// Deep copy the slices
+	copy(clone.patterns, s.patterns)
+	copy(clone.formats, s.formats)
 	copy(clone.validators, s.validators)
+	copy(clone.preprocessors, s.preprocessors)
+	copy(clone.transforms, s.transforms)
 
 	// Deep copy pointer fields
 	if s.minLength != nil {
@@ -268,6 +520,10 @@ func (s *StringSchema) Clone() Schema {
 	// Initialize a new validation result
 	clone.result = &ValidationResult{}
 
+	clone.defaultValue = s.defaultValue
+	clone.isNullable = s.isNullable
+	clone.isNull = s.isNull
+
 	return clone
 }
 
@@ -275,7 +531,7 @@ func (s *StringSchema) Clone() Schema {
 // the string value is a null pointer due to it not being set
 func (s *StringSchema) Value() (string, bool) {
 	val, ok := s.getValue()
-	if !ok {
+	if !ok || val == nil {
 		return "", false
 	}
 	strVal, ok := val.(string)
@@ -286,6 +542,10 @@ func (s *StringSchema) Value() (string, bool) {
 }
 
 func (s *StringSchema) getValue() (interface{}, bool) {
+	if s.isNull {
+		return nil, true
+	}
+
 	if s.value == nil {
 		return nil, false
 	}
@@ -0,0 +1,198 @@
+package gsv
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	PatternStringError    ValidationErrorType = "pattern_string"
+	StartsWithStringError ValidationErrorType = "starts_with_string"
+	EndsWithStringError   ValidationErrorType = "ends_with_string"
+	IncludesStringError   ValidationErrorType = "includes_string"
+	EmailStringError      ValidationErrorType = "email_string"
+	URLStringError        ValidationErrorType = "url_string"
+	UUIDStringError       ValidationErrorType = "uuid_string"
+	IPv4StringError       ValidationErrorType = "ipv4_string"
+	IPv6StringError       ValidationErrorType = "ipv6_string"
+	HostnameStringError   ValidationErrorType = "hostname_string"
+	DateTimeStringError   ValidationErrorType = "date_time_string"
+	DateStringError       ValidationErrorType = "date_string"
+	DurationStringError   ValidationErrorType = "duration_string"
+)
+
+var (
+	// emailRegex is the "valid email address" definition of the HTML spec,
+	// which is what most people expect an email address to look like
+	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	hostnameLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+	// durationRegex matches ISO 8601 durations like "P1DT12H" or "P2W"
+	durationRegex = regexp.MustCompile(`^P(?:(?:\d+Y)?(?:\d+M)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+(?:\.\d+)?S)?)?|\d+W)$`)
+)
+
+// Regex adds validation that the string matches the regular expression. It
+// panics if the pattern doesn't compile.
+func (v *StringSchema) Regex(pattern string, opts ...ValidationOptions) *StringSchema {
+	re := regexp.MustCompile(pattern)
+
+	v.patterns = append(v.patterns, pattern)
+	return v.addCheck(PatternStringError, pattern, re.MatchString,
+		fmt.Sprintf("must match pattern %s", pattern), opts)
+}
+
+// StartsWith adds validation that the string starts with the prefix
+func (v *StringSchema) StartsWith(prefix string, opts ...ValidationOptions) *StringSchema {
+	v.patterns = append(v.patterns, "^"+regexp.QuoteMeta(prefix))
+	return v.addCheck(StartsWithStringError, prefix, func(s string) bool {
+		return strings.HasPrefix(s, prefix)
+	}, fmt.Sprintf("must start with %q", prefix), opts)
+}
+
+// EndsWith adds validation that the string ends with the suffix
+func (v *StringSchema) EndsWith(suffix string, opts ...ValidationOptions) *StringSchema {
+	v.patterns = append(v.patterns, regexp.QuoteMeta(suffix)+"$")
+	return v.addCheck(EndsWithStringError, suffix, func(s string) bool {
+		return strings.HasSuffix(s, suffix)
+	}, fmt.Sprintf("must end with %q", suffix), opts)
+}
+
+// Includes adds validation that the string contains the substring
+func (v *StringSchema) Includes(substr string, opts ...ValidationOptions) *StringSchema {
+	v.patterns = append(v.patterns, regexp.QuoteMeta(substr))
+	return v.addCheck(IncludesStringError, substr, func(s string) bool {
+		return strings.Contains(s, substr)
+	}, fmt.Sprintf("must include %q", substr), opts)
+}
+
+// Email adds validation that the string is an email address
+func (v *StringSchema) Email(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "email")
+	return v.addCheck(EmailStringError, "email", emailRegex.MatchString,
+		"must be a valid email address", opts)
+}
+
+// URL adds validation that the string is an absolute URL, like
+// "https://example.com/path"
+func (v *StringSchema) URL(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "uri")
+	return v.addCheck(URLStringError, "uri", isURL, "must be a valid URL", opts)
+}
+
+// UUID adds validation that the string is a UUID in its canonical form
+func (v *StringSchema) UUID(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "uuid")
+	return v.addCheck(UUIDStringError, "uuid", uuidRegex.MatchString,
+		"must be a valid UUID", opts)
+}
+
+// IPv4 adds validation that the string is an IPv4 address in dotted decimal
+// notation
+func (v *StringSchema) IPv4(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "ipv4")
+	return v.addCheck(IPv4StringError, "ipv4", func(s string) bool {
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is4()
+	}, "must be a valid IPv4 address", opts)
+}
+
+// IPv6 adds validation that the string is an IPv6 address
+func (v *StringSchema) IPv6(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "ipv6")
+	return v.addCheck(IPv6StringError, "ipv6", func(s string) bool {
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is6() && addr.Zone() == ""
+	}, "must be a valid IPv6 address", opts)
+}
+
+// Hostname adds validation that the string is a hostname as defined by
+// RFC 1123
+func (v *StringSchema) Hostname(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "hostname")
+	return v.addCheck(HostnameStringError, "hostname", isHostname,
+		"must be a valid hostname", opts)
+}
+
+// DateTime adds validation that the string is an RFC 3339 date-time, like
+// "2024-01-02T15:04:05Z"
+func (v *StringSchema) DateTime(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "date-time")
+	return v.addCheck(DateTimeStringError, "date-time", func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	}, "must be a valid RFC 3339 date-time", opts)
+}
+
+// Date adds validation that the string is a full date, like "2024-01-02"
+func (v *StringSchema) Date(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "date")
+	return v.addCheck(DateStringError, "date", func(s string) bool {
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	}, "must be a valid date", opts)
+}
+
+// Duration adds validation that the string is an ISO 8601 duration, like
+// "P1DT12H"
+func (v *StringSchema) Duration(opts ...ValidationOptions) *StringSchema {
+	v.formats = append(v.formats, "duration")
+	return v.addCheck(DurationStringError, "duration", func(s string) bool {
+		return durationRegex.MatchString(s) && s != "P" && !strings.HasSuffix(s, "T")
+	}, "must be a valid ISO 8601 duration", opts)
+}
+
+// addCheck registers a validator that reports errType with the message, or
+// the message given in the options, when valid returns false
+func (v *StringSchema) addCheck(errType ValidationErrorType, expected string, valid func(string) bool, message string, opts []ValidationOptions) *StringSchema {
+	if len(opts) > 0 && opts[0].Message != "" {
+		message = opts[0].Message
+	}
+
+	v.validators = append(v.validators, func(s string) *ValidationError {
+		if !valid(s) {
+			return &ValidationError{
+				Type:     errType,
+				Message:  message,
+				Expected: expected,
+				Actual:   s,
+			}
+		}
+		return nil
+	})
+
+	return v
+}
+
+// isURL reports whether s is an absolute URL with a scheme and either a host,
+// like "https://example.com", or an opaque part, like "mailto:a@example.com"
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+}
+
+// isHostname reports whether s is made of dot separated labels of at most 63
+// letters, digits and hyphens that don't start or end with a hyphen
+func isHostname(s string) bool {
+	if s == "" || len(s) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(s, ".") {
+		if !hostnameLabelRegex.MatchString(label) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,156 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Coercion", func() {
+	type TestSettingsSchema struct {
+		Count   *gsv.NumberSchema[int]     `json:"count"`
+		Ratio   *gsv.NumberSchema[float64] `json:"ratio"`
+		Enabled *gsv.BoolSchema            `json:"enabled"`
+		Tags    *gsv.ArraySchema           `json:"tags"`
+	}
+
+	newSchema := func() *TestSettingsSchema {
+		return &TestSettingsSchema{
+			Count:   gsv.Int().Min(1),
+			Ratio:   gsv.Float64(),
+			Enabled: gsv.Bool(),
+			Tags:    gsv.Array(gsv.String()),
+		}
+	}
+
+	Context("with the Coerce parse option", func() {
+		It("coerces every schema and reports warnings", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{
+				"count": "5",
+				"ratio": " 0.5 ",
+				"enabled": "Yes",
+				"tags": "go"
+			}`), schema, gsv.ParseOptions{Coerce: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+
+			count, _ := schema.Count.Value()
+			Expect(count).To(Equal(5))
+			ratio, _ := schema.Ratio.Value()
+			Expect(ratio).To(Equal(0.5))
+			enabled, _ := schema.Enabled.Value()
+			Expect(enabled).To(BeTrue())
+			tags, _ := schema.Tags.Value()
+			Expect(tags).To(Equal([]interface{}{"go"}))
+
+			Expect(result.Warnings).To(HaveLen(4))
+			Expect(result.Warnings[0].Type).To(Equal(gsv.CoercionWarning))
+			Expect(result.Warnings[0].Field).To(Equal("/count"))
+			Expect(result.Warnings[0].Message).To(Equal(`coerced string "5" to integer`))
+			Expect(result.Warnings[0].Expected).To(Equal("integer"))
+			Expect(result.Warnings[0].Actual).To(Equal("5"))
+			Expect(result.Warnings[1].Field).To(Equal("/ratio"))
+			Expect(result.Warnings[2].Field).To(Equal("/enabled"))
+			Expect(result.Warnings[2].Expected).To(Equal("boolean"))
+			Expect(result.Warnings[3].Field).To(Equal("/tags"))
+			Expect(result.Warnings[3].Expected).To(Equal("array"))
+		})
+
+		It("doesn't warn about values of the right type", func() {
+			result, err := gsv.Parse([]byte(`{"count": 5, "ratio": 0.5, "enabled": false, "tags": ["go"]}`),
+				newSchema(), gsv.ParseOptions{Coerce: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+			Expect(result.HasWarnings()).To(BeFalse())
+		})
+
+		It("still validates coerced values", func() {
+			result, err := gsv.Parse([]byte(`{"count": "0", "ratio": 1, "enabled": "off", "tags": []}`),
+				newSchema(), gsv.ParseOptions{Coerce: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MinNumberError))
+			Expect(result.Warnings).To(HaveLen(2))
+		})
+
+		It("reports the paths of nested warnings", func() {
+			schema := &struct {
+				Items *gsv.ArraySchema `json:"items"`
+			}{
+				Items: gsv.Array(gsv.Object(map[string]gsv.Schema{
+					"qty": gsv.Int(),
+				})),
+			}
+
+			result, err := gsv.Parse([]byte(`{"items": [{"qty": 1}, {"qty": "2"}]}`), schema,
+				gsv.ParseOptions{Coerce: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+			Expect(result.Warnings).To(HaveLen(1))
+			Expect(result.Warnings[0].Field).To(Equal("/items/1/qty"))
+		})
+
+		It("fails on values that can't be coerced", func() {
+			_, err := gsv.Parse([]byte(`{"count": "five"}`), newSchema(), gsv.ParseOptions{Coerce: true})
+			Expect(err).To(HaveOccurred())
+
+			_, err = gsv.Parse([]byte(`{"enabled": "maybe"}`), newSchema(), gsv.ParseOptions{Coerce: true})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("without coercion", func() {
+		It("rejects values of the wrong type", func() {
+			_, err := gsv.Parse([]byte(`{"count": "5"}`), newSchema())
+			Expect(err).To(HaveOccurred())
+
+			_, err = gsv.Parse([]byte(`{"enabled": "yes"}`), newSchema())
+			Expect(err).To(HaveOccurred())
+
+			_, err = gsv.Parse([]byte(`{"tags": "go"}`), newSchema())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with Coerce on a schema", func() {
+		It("coerces only that schema", func() {
+			schema := newSchema()
+			schema.Enabled.Coerce()
+
+			result, err := gsv.Parse([]byte(`{"count": 1, "ratio": 1, "enabled": "no", "tags": []}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+			Expect(result.Warnings).To(HaveLen(1))
+
+			enabled, _ := schema.Enabled.Value()
+			Expect(enabled).To(BeFalse())
+
+			_, err = gsv.Parse([]byte(`{"count": "1"}`), schema)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("coerces when unmarshaling", func() {
+			schema := gsv.Int().Coerce()
+			Expect(json.Unmarshal([]byte(`"42"`), schema)).To(Succeed())
+
+			val, _ := schema.Value()
+			Expect(val).To(Equal(42))
+			Expect(schema.Validate().Warnings).To(HaveLen(1))
+
+			Expect(schema.Set(7).Validate().HasWarnings()).To(BeFalse())
+		})
+
+		It("coerces the numbers 1 and 0 into booleans", func() {
+			schema := gsv.Bool().Coerce()
+			Expect(json.Unmarshal([]byte(`1`), schema)).To(Succeed())
+
+			val, _ := schema.Value()
+			Expect(val).To(BeTrue())
+			Expect(schema.Validate().Warnings[0].Actual).To(Equal(float64(1)))
+		})
+	})
+})
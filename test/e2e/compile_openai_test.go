@@ -0,0 +1,141 @@
+package gsv_e2e_test
+
+import (
+	"errors"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenAI strict mode compile target", func() {
+	opts := &gsv.CompileSchemaOpts{SchemaTitle: "weather"}
+
+	It("requires every property and disallows additional ones", func() {
+		schema := &struct {
+			City     *gsv.StringSchema       `json:"city"`
+			Unit     *gsv.EnumSchema[string] `json:"unit"`
+			Days     *gsv.NumberSchema[int]  `json:"days"`
+			Location *gsv.ObjectSchema       `json:"location"`
+		}{
+			City: gsv.String().Min(1).Max(64).Description("The city"),
+			Unit: gsv.Enum("c", "f").Optional(),
+			Days: gsv.Int().Min(1).Default(3),
+			Location: gsv.Object(map[string]gsv.Schema{
+				"lat":  gsv.Float64(),
+				"note": gsv.String().Optional(),
+			}).Optional(),
+		}
+
+		compiled, err := gsv.CompileSchema(schema, opts, gsv.TargetOpenAIStrict)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"title": "weather",
+			"type": "object",
+			"properties": {
+				"city": {"type": "string", "description": "The city"},
+				"unit": {"type": ["string", "null"], "enum": ["c", "f", null]},
+				"days": {"type": ["integer", "null"], "minimum": 1},
+				"location": {
+					"type": ["object", "null"],
+					"properties": {
+						"lat": {"type": "number"},
+						"note": {"type": ["string", "null"]}
+					},
+					"required": ["lat", "note"],
+					"additionalProperties": false
+				}
+			},
+			"required": ["city", "days", "location", "unit"],
+			"additionalProperties": false
+		}`))
+	})
+
+	It("compiles unions to anyOf", func() {
+		schema := &struct {
+			Shape  *gsv.DiscriminatedUnionSchema `json:"shape"`
+			Answer *gsv.UnionSchema              `json:"answer"`
+			Kind   *gsv.EnumSchema[string]       `json:"kind"`
+		}{
+			Shape: gsv.DiscriminatedUnion("type", map[string]*gsv.ObjectSchema{
+				"circle": gsv.Object(map[string]gsv.Schema{"radius": gsv.Float64()}),
+			}),
+			Answer: gsv.Union(gsv.String(), gsv.Int()).Optional(),
+			Kind:   gsv.Literal("weather"),
+		}
+
+		compiled, err := gsv.CompileSchema(schema, opts, gsv.TargetOpenAIStrict)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"title": "weather",
+			"type": "object",
+			"properties": {
+				"shape": {"anyOf": [{
+					"type": "object",
+					"properties": {
+						"radius": {"type": "number"},
+						"type": {"type": "string", "enum": ["circle"]}
+					},
+					"required": ["radius", "type"],
+					"additionalProperties": false
+				}]},
+				"answer": {"anyOf": [{"type": "string"}, {"type": "integer"}, {"type": "null"}]},
+				"kind": {"type": "string", "enum": ["weather"]}
+			},
+			"required": ["shape", "kind", "answer"],
+			"additionalProperties": false
+		}`))
+	})
+
+	It("drops keywords strict mode doesn't support", func() {
+		schema := &struct {
+			Site *gsv.StringSchema `json:"site"`
+			Mail *gsv.StringSchema `json:"mail"`
+		}{
+			Site: gsv.String().URL().Regex(`^https`),
+			Mail: gsv.String().Email().Regex(`@example\.com$`),
+		}
+
+		compiled, err := gsv.CompileSchema(schema, opts, gsv.TargetOpenAIStrict)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"title": "weather",
+			"type": "object",
+			"properties": {
+				"site": {"type": "string", "pattern": "^https"},
+				"mail": {"type": "string", "pattern": "@example\\.com$", "format": "email"}
+			},
+			"required": ["site", "mail"],
+			"additionalProperties": false
+		}`))
+	})
+
+	It("lists the constructs that can't be represented", func() {
+		schema := &struct {
+			Headers *gsv.RecordSchema `json:"headers"`
+			Extra   *gsv.ObjectSchema `json:"extra"`
+		}{
+			Headers: gsv.Record(gsv.String(), gsv.String()),
+			Extra:   gsv.Object(map[string]gsv.Schema{}).Catchall(gsv.Int()),
+		}
+
+		_, err := gsv.CompileSchema(schema, opts, gsv.TargetOpenAIStrict)
+		Expect(err).To(HaveOccurred())
+
+		var unsupported *gsv.UnsupportedSchemaError
+		Expect(errors.As(err, &unsupported)).To(BeTrue())
+		Expect(unsupported.Constructs).To(Equal([]string{
+			"/properties/extra: objects with dynamic keys, like records and catchall objects",
+			"/properties/headers: objects with dynamic keys, like records and catchall objects",
+		}))
+		Expect(err.Error()).To(HavePrefix("schema can't be compiled for OpenAI strict mode: "))
+	})
+
+	It("requires an object at the root", func() {
+		_, err := gsv.CompileSchema(gsv.String(), opts, gsv.TargetOpenAIStrict)
+		Expect(err).To(MatchError(ContainSubstring("/: the root schema must be an object")))
+	})
+})
@@ -180,6 +180,100 @@ var _ = Describe("Schema Compiler", func() {
 		})
 	})
 
+	Context("when compiling other schema types", func() {
+		type NestedAllTypesSchema struct {
+			Flag *gsv.BoolSchema `json:"flag"`
+		}
+
+		type AllTypesSchema struct {
+			Enabled *gsv.BoolSchema       `json:"enabled"`
+			Count   *gsv.IntSchema        `json:"count"`
+			Big     *gsv.Int64Schema      `json:"big"`
+			Small   *gsv.Uint8Schema      `json:"small,omitempty"`
+			Ratio   *gsv.Float64Schema    `json:"ratio"`
+			Tags    *gsv.ArraySchema      `json:"tags"`
+			Labels  *gsv.ObjectSchema     `json:"labels"`
+			Nested  *NestedAllTypesSchema `json:"nested"`
+		}
+
+		var properties map[string]interface{}
+
+		BeforeEach(func() {
+			schema := AllTypesSchema{
+				Enabled: gsv.Bool(),
+				Count:   gsv.Int(),
+				Big:     gsv.Int64().Optional(),
+				Small:   gsv.Uint8(),
+				Ratio:   gsv.Float64(),
+				Tags:    gsv.Array(gsv.String()).MinItems(1),
+				Labels: gsv.Object(map[string]gsv.Schema{
+					"color": gsv.String(),
+				}),
+				Nested: &NestedAllTypesSchema{Flag: gsv.Bool()},
+			}
+
+			result, err := gsv.CompileSchema(schema, &gsv.CompileSchemaOpts{SchemaTitle: "all_types"})
+			Expect(err).NotTo(HaveOccurred())
+
+			var jsonSchema map[string]interface{}
+			Expect(json.Unmarshal(result, &jsonSchema)).To(Succeed())
+
+			properties = jsonSchema["properties"].(map[string]interface{})
+		})
+
+		It("should compile bool schemas", func() {
+			Expect(properties["enabled"]).To(Equal(map[string]interface{}{"type": "boolean"}))
+		})
+
+		It("should compile integer schemas as integers", func() {
+			Expect(properties["count"]).To(Equal(map[string]interface{}{"type": "integer"}))
+			Expect(properties["big"]).To(Equal(map[string]interface{}{"type": "integer"}))
+			Expect(properties["small"]).To(Equal(map[string]interface{}{"type": "integer"}))
+		})
+
+		It("should compile float schemas as numbers", func() {
+			Expect(properties["ratio"]).To(Equal(map[string]interface{}{"type": "number"}))
+		})
+
+		It("should compile array schemas with their items", func() {
+			Expect(properties["tags"]).To(Equal(map[string]interface{}{
+				"type":     "array",
+				"items":    map[string]interface{}{"type": "string"},
+				"minItems": float64(1),
+			}))
+		})
+
+		It("should compile object schemas with their shape", func() {
+			labels := properties["labels"].(map[string]interface{})
+			Expect(labels["type"]).To(Equal("object"))
+			Expect(labels["properties"]).To(HaveKey("color"))
+		})
+
+		It("should compile schemas in nested structs", func() {
+			nested := properties["nested"].(map[string]interface{})
+			Expect(nested["properties"]).To(HaveKeyWithValue("flag", map[string]interface{}{"type": "boolean"}))
+		})
+	})
+
+	Context("when compiling a single schema", func() {
+		It("should compile the schema as the root", func() {
+			schema := gsv.Object(map[string]gsv.Schema{
+				"name": gsv.String(),
+			}).Strict()
+
+			result, err := gsv.CompileSchema(schema, &gsv.CompileSchemaOpts{SchemaTitle: "object"})
+			Expect(err).NotTo(HaveOccurred())
+
+			var jsonSchema map[string]interface{}
+			Expect(json.Unmarshal(result, &jsonSchema)).To(Succeed())
+
+			Expect(jsonSchema["title"]).To(Equal("object"))
+			Expect(jsonSchema["type"]).To(Equal("object"))
+			Expect(jsonSchema["properties"]).To(HaveKey("name"))
+			Expect(jsonSchema["additionalProperties"]).To(Equal(false))
+		})
+	})
+
 	Context("when handling edge cases", func() {
 		type EdgeCaseSchema struct {
 			EmptyDescription *gsv.StringSchema `json:"emptyDesc"`
@@ -0,0 +1,196 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Default", func() {
+	type TestSearchSchema struct {
+		Query   *gsv.StringSchema          `json:"query"`
+		Limit   *gsv.NumberSchema[int]     `json:"limit"`
+		Exact   *gsv.BoolSchema            `json:"exact"`
+		Sort    *gsv.EnumSchema[string]    `json:"sort"`
+		Fields  *gsv.ArraySchema           `json:"fields"`
+		Filters *gsv.RecordSchema          `json:"filters"`
+		Page    *gsv.ObjectSchema          `json:"page"`
+		Cursor  *gsv.StringSchema          `json:"cursor"`
+		Score   *gsv.NumberSchema[float64] `json:"score"`
+	}
+
+	newSchema := func() *TestSearchSchema {
+		calls := 0
+		return &TestSearchSchema{
+			Query:   gsv.String(),
+			Limit:   gsv.Int().Max(100).Default(10),
+			Exact:   gsv.Bool().Default(false),
+			Sort:    gsv.Enum("relevance", "date").Default("relevance"),
+			Fields:  gsv.Array(gsv.String()).Default([]interface{}{"title"}),
+			Filters: gsv.Record(gsv.String(), gsv.String()).Default(map[string]interface{}{}),
+			Page: gsv.Object(map[string]gsv.Schema{
+				"size": gsv.Int(),
+			}).Default(map[string]interface{}{"size": 20}),
+			Cursor: gsv.String().DefaultFunc(func() string {
+				calls++
+				return strings.Repeat("*", calls)
+			}),
+			Score: gsv.Float64().Optional(),
+		}
+	}
+
+	It("fills in missing fields when parsing", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"query": "gsv"}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.HasErrors()).To(BeFalse())
+
+		limit, ok := schema.Limit.Value()
+		Expect(ok).To(BeTrue())
+		Expect(limit).To(Equal(10))
+
+		exact, ok := schema.Exact.Value()
+		Expect(ok).To(BeTrue())
+		Expect(exact).To(BeFalse())
+
+		sort, _ := schema.Sort.Value()
+		Expect(sort).To(Equal("relevance"))
+
+		fields, _ := schema.Fields.Value()
+		Expect(fields).To(Equal([]interface{}{"title"}))
+
+		filters, ok := schema.Filters.Value()
+		Expect(ok).To(BeTrue())
+		Expect(filters).To(BeEmpty())
+
+		page, _ := schema.Page.Value()
+		Expect(page).To(Equal(map[string]interface{}{"size": 20}))
+
+		_, ok = schema.Score.Value()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("keeps the values that are given", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"query": "gsv", "limit": 50, "sort": "date"}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.HasErrors()).To(BeFalse())
+
+		limit, _ := schema.Limit.Value()
+		Expect(limit).To(Equal(50))
+		sort, _ := schema.Sort.Value()
+		Expect(sort).To(Equal("date"))
+	})
+
+	It("applies the default when decoding, not when reading", func() {
+		limit := gsv.Int().Default(10)
+
+		_, ok := limit.Value()
+		Expect(ok).To(BeFalse())
+		Expect(limit.State()).To(Equal(gsv.Absent))
+
+		schema := newSchema()
+		_, err := gsv.Parse([]byte(`{"query": "gsv"}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schema.Limit.State()).To(Equal(gsv.Present))
+
+		data, err := json.Marshal(schema.Limit)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("10"))
+	})
+
+	It("fills in missing keys of object schemas", func() {
+		schema := gsv.Object(map[string]gsv.Schema{
+			"size": gsv.Int().Default(20),
+		})
+
+		Expect(json.Unmarshal([]byte(`{}`), schema)).To(Succeed())
+
+		page, _ := schema.Value()
+		Expect(page).To(Equal(map[string]interface{}{"size": 20}))
+	})
+
+	It("builds the default once per missing value with DefaultFunc", func() {
+		schema := newSchema()
+
+		_, err := gsv.Parse([]byte(`{"query": "gsv"}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		cursor, _ := schema.Cursor.Value()
+		Expect(cursor).To(Equal("*"))
+		cursor, _ = schema.Cursor.Value()
+		Expect(cursor).To(Equal("*"))
+
+		_, err = gsv.Parse([]byte(`{"query": "gsv"}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		cursor, _ = schema.Cursor.Value()
+		Expect(cursor).To(Equal("**"))
+	})
+
+	It("validates the default", func() {
+		schema := &struct {
+			Limit *gsv.NumberSchema[int] `json:"limit"`
+		}{
+			Limit: gsv.Int().Max(5).Default(10),
+		}
+
+		result, err := gsv.Parse([]byte(`{}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.MaxNumberError))
+	})
+
+	It("fills in the inner schema of a transform", func() {
+		schema := &struct {
+			Tags *gsv.TransformSchema[string, []string] `json:"tags"`
+		}{
+			Tags: gsv.Transform(gsv.String().Default("a,b"), func(s string) ([]string, error) {
+				return strings.Split(s, ","), nil
+			}),
+		}
+
+		result, err := gsv.Parse([]byte(`{}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.HasErrors()).To(BeFalse())
+
+		val, ok := schema.Tags.Value()
+		Expect(ok).To(BeTrue())
+		Expect(val).To(Equal([]string{"a", "b"}))
+	})
+
+	It("panics on defaults the schema doesn't accept", func() {
+		Expect(func() {
+			gsv.Object(map[string]gsv.Schema{"size": gsv.Int()}).Default(map[string]interface{}{"size": "20"})
+		}).To(Panic())
+	})
+
+	It("compiles to the default keyword", func() {
+		compiled, err := gsv.CompileSchema(newSchema(), &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"type": "object",
+			"properties": {
+				"query": {"type": "string"},
+				"limit": {"type": "integer", "maximum": 100, "default": 10},
+				"exact": {"type": "boolean", "default": false},
+				"sort": {"type": "string", "enum": ["relevance", "date"], "default": "relevance"},
+				"fields": {"type": "array", "items": {"type": "string"}, "default": ["title"]},
+				"filters": {"type": "object", "additionalProperties": {"type": "string"}, "default": {}},
+				"page": {
+					"type": "object",
+					"properties": {"size": {"type": "integer"}},
+					"required": ["size"],
+					"default": {"size": 20}
+				},
+				"cursor": {"type": "string"},
+				"score": {"type": "number"}
+			},
+			"required": ["query"]
+		}`))
+	})
+})
@@ -0,0 +1,104 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnumSchema", func() {
+	Context("with Enum", func() {
+		It("accepts an allowed value", func() {
+			schema := gsv.Enum("celsius", "fahrenheit")
+
+			err := json.Unmarshal([]byte(`"celsius"`), schema)
+			Expect(err).ToNot(HaveOccurred())
+
+			val, ok := schema.Value()
+			Expect(ok).To(BeTrue())
+			Expect(val).To(Equal("celsius"))
+		})
+
+		It("reports the allowed set for other values", func() {
+			schema := gsv.Enum("celsius", "fahrenheit").Set("kelvin")
+
+			result := schema.Validate()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.InvalidEnumValueError))
+			Expect(result.Errors[0].Message).To(Equal(`must be one of "celsius", "fahrenheit"`))
+			Expect(result.Errors[0].Expected).To(Equal([]string{"celsius", "fahrenheit"}))
+			Expect(result.Errors[0].Actual).To(Equal("kelvin"))
+		})
+
+		It("validates numbers", func() {
+			schema := gsv.Enum(1, 2, 4)
+
+			Expect(json.Unmarshal([]byte(`2`), schema)).To(Succeed())
+			Expect(json.Unmarshal([]byte(`3`), schema)).ToNot(Succeed())
+		})
+
+		It("requires a value unless optional", func() {
+			Expect(gsv.Enum("a").Validate().Errors[0].Type).To(Equal(gsv.RequiredEnumError))
+			Expect(gsv.Enum("a").Optional().Validate().HasErrors()).To(BeFalse())
+		})
+	})
+
+	Context("with Literal", func() {
+		It("only accepts the literal value", func() {
+			schema := gsv.Literal("search")
+
+			Expect(schema.Set("search").Validate().HasErrors()).To(BeFalse())
+
+			result := schema.Set("fetch").Validate()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.InvalidLiteralError))
+			Expect(result.Errors[0].Message).To(Equal(`must be "search"`))
+			Expect(result.Errors[0].Expected).To(Equal("search"))
+		})
+	})
+
+	Context("when parsing a struct", func() {
+		type TestWeatherSchema struct {
+			Unit *gsv.EnumSchema[string] `json:"unit"`
+		}
+
+		It("reports the field of invalid values", func() {
+			schema := &TestWeatherSchema{Unit: gsv.Enum("celsius", "fahrenheit")}
+
+			result, err := gsv.Parse([]byte(`{"unit": "kelvin"}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Field).To(Equal("/unit"))
+			Expect(result.Errors[0].Type).To(Equal(gsv.InvalidEnumValueError))
+		})
+	})
+
+	Context("when compiling", func() {
+		It("emits enum", func() {
+			compiled, err := gsv.CompileSchema(gsv.Enum("celsius", "fahrenheit").Description("The unit"), &gsv.CompileSchemaOpts{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(compiled)).To(MatchJSON(`{
+				"type": "string",
+				"description": "The unit",
+				"enum": ["celsius", "fahrenheit"]
+			}`))
+		})
+
+		It("emits const", func() {
+			compiled, err := gsv.CompileSchema(gsv.Literal(3), &gsv.CompileSchemaOpts{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(compiled)).To(MatchJSON(`{"type": "integer", "const": 3}`))
+		})
+
+		It("omits the type for mixed values", func() {
+			compiled, err := gsv.CompileSchema(gsv.Enum[interface{}]("auto", 1.0), &gsv.CompileSchemaOpts{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(compiled)).To(MatchJSON(`{"enum": ["auto", 1]}`))
+		})
+	})
+})
@@ -22,4 +22,62 @@ var _ = Describe("Schema Errors", func() {
 			Expect(err.Actual).To(Equal(3))
 		})
 	})
+
+	Context("Field paths", func() {
+		type DeeperSchema struct {
+			DeeperScore *gsv.IntSchema `json:"deeper_score"`
+		}
+
+		type PathSchema struct {
+			Deeper *DeeperSchema    `json:"deeper"`
+			Items  *gsv.ArraySchema `json:"items"`
+			Odd    *gsv.IntSchema   `json:"a/b~c"`
+		}
+
+		var result *gsv.ValidationResult
+
+		BeforeEach(func() {
+			schema := &PathSchema{
+				Deeper: &DeeperSchema{DeeperScore: gsv.Int().Max(99)},
+				Items: gsv.Array(gsv.Object(map[string]gsv.Schema{
+					"name": gsv.String().Min(3),
+				})),
+				Odd: gsv.Int().Min(1),
+			}
+
+			var err error
+			result, err = gsv.Parse([]byte(`{
+				"deeper": {"deeper_score": 100},
+				"items": [{"name": "abc"}, {"name": "ab"}],
+				"a/b~c": 0
+			}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(3))
+		})
+
+		It("uses JSON tags for struct fields", func() {
+			err := result.Errors[0]
+			Expect(err.Field).To(Equal("/deeper/deeper_score"))
+			Expect(err.Path).To(Equal([]interface{}{"deeper", "deeper_score"}))
+			Expect(err.DottedPath()).To(Equal("deeper.deeper_score"))
+		})
+
+		It("uses indices for array elements", func() {
+			err := result.Errors[1]
+			Expect(err.Field).To(Equal("/items/1/name"))
+			Expect(err.Path).To(Equal([]interface{}{"items", 1, "name"}))
+			Expect(err.DottedPath()).To(Equal("items[1].name"))
+			Expect(err.Message).To(Equal("must be at least 3 characters long"))
+		})
+
+		It("escapes JSON Pointer special characters", func() {
+			err := result.Errors[2]
+			Expect(err.Field).To(Equal("/a~1b~0c"))
+			Expect(err.Path).To(Equal([]interface{}{"a/b~c"}))
+		})
+
+		It("uses the dotted path in the combined error", func() {
+			Expect(result.Error().Error()).To(ContainSubstring("items[1].name: [min_string_length]"))
+		})
+	})
 })
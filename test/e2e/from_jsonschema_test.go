@@ -0,0 +1,254 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FromJSONSchema", func() {
+	It("builds a schema that compiles back to the same JSON schema", func() {
+		compiled, err := gsv.CompileSchema(&struct {
+			City  *gsv.StringSchema          `json:"city"`
+			Days  *gsv.NumberSchema[int64]   `json:"days"`
+			Place *gsv.ObjectSchema          `json:"place"`
+			Tags  *gsv.ArraySchema           `json:"tags"`
+			Temp  *gsv.NumberSchema[float64] `json:"temp"`
+			Unit  *gsv.EnumSchema[string]    `json:"unit"`
+		}{
+			City: gsv.String().Min(1).Max(20).Description("The city"),
+			Days: gsv.Int64().Min(1).Max(7),
+			Place: gsv.Object(map[string]gsv.Schema{
+				"lat": gsv.Float64().Min(-90).Max(90),
+				"lng": gsv.Float64().Optional(),
+			}),
+			Tags: gsv.Array(gsv.String().Email()).MaxItems(3),
+			Temp: gsv.Float64().Nullable(),
+			Unit: gsv.Enum("c", "f").Optional(),
+		}, &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		schema, err := gsv.FromJSONSchema(compiled)
+		Expect(err).ToNot(HaveOccurred())
+
+		recompiled, err := gsv.CompileSchema(schema, &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(recompiled)).To(MatchJSON(compiled))
+	})
+
+	It("validates values against the built schema", func() {
+		schema, err := gsv.FromJSONSchema([]byte(`{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"age": {"type": "integer", "minimum": 0},
+				"role": {"enum": ["admin", "user"]},
+				"email": {"type": "string", "format": "email"}
+			},
+			"required": ["name", "age"],
+			"additionalProperties": false
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(json.Unmarshal([]byte(`{"name": "Ada", "age": 36, "role": "admin"}`), schema)).To(Succeed())
+
+		err = json.Unmarshal([]byte(`{"name": "", "age": -1, "role": "root", "email": "nope"}`), schema)
+		Expect(err).To(HaveOccurred())
+		Expect(schema.Validate().Errors).To(HaveLen(4))
+
+		err = json.Unmarshal([]byte(`{"name": "Ada", "age": 36, "extra": true}`), schema)
+		Expect(err).To(HaveOccurred())
+
+		err = json.Unmarshal([]byte(`{"name": "Ada"}`), schema)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("resolves local references", func() {
+		schema, err := gsv.FromJSONSchema([]byte(`{
+			"type": "object",
+			"properties": {
+				"home": {"$ref": "#/definitions/address"},
+				"work": {"$ref": "#/$defs/address"}
+			},
+			"required": ["home"],
+			"definitions": {
+				"address": {
+					"type": "object",
+					"properties": {"zip": {"type": "string", "pattern": "^[0-9]{5}$"}},
+					"required": ["zip"]
+				}
+			},
+			"$defs": {
+				"address": {"$ref": "#/definitions/address"}
+			}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(json.Unmarshal([]byte(`{"home": {"zip": "12345"}, "work": {"zip": "54321"}}`), schema)).To(Succeed())
+		Expect(json.Unmarshal([]byte(`{"home": {"zip": "12345"}, "work": {"zip": "abc"}}`), schema)).ToNot(Succeed())
+	})
+
+	It("builds nullable types, unions and discriminated unions", func() {
+		schema, err := gsv.FromJSONSchema([]byte(`{
+			"type": "object",
+			"properties": {
+				"note": {"type": ["string", "null"]},
+				"id": {"anyOf": [{"type": "string"}, {"type": "integer"}]},
+				"shape": {
+					"oneOf": [
+						{
+							"type": "object",
+							"properties": {"kind": {"const": "circle"}, "radius": {"type": "number"}},
+							"required": ["kind", "radius"]
+						},
+						{
+							"type": "object",
+							"properties": {"kind": {"const": "square"}, "side": {"type": "number"}},
+							"required": ["kind", "side"]
+						}
+					]
+				}
+			},
+			"required": ["note", "id", "shape"]
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(json.Unmarshal([]byte(`{"note": null, "id": 7, "shape": {"kind": "circle", "radius": 1}}`), schema)).To(Succeed())
+		Expect(json.Unmarshal([]byte(`{"note": "hi", "id": "a", "shape": {"kind": "square", "side": 2}}`), schema)).To(Succeed())
+		Expect(json.Unmarshal([]byte(`{"note": 1, "id": true, "shape": {"kind": "square", "radius": 2}}`), schema)).ToNot(Succeed())
+	})
+
+	It("accepts any value for the empty schema", func() {
+		schema, err := gsv.FromJSONSchema([]byte(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, value := range []string{`1`, `"a"`, `null`, `[true]`, `{"a": {}}`} {
+			Expect(json.Unmarshal([]byte(value), schema)).To(Succeed(), value)
+		}
+	})
+
+	It("reports unsupported keywords", func() {
+		_, err := gsv.FromJSONSchema([]byte(`{
+			"type": "object",
+			"properties": {
+				"a": {"type": "string", "not": {"const": "x"}},
+				"b": {"$ref": "https://example.com/b.json"},
+				"c": {"type": "integer", "if": {}, "then": {}}
+			}
+		}`))
+
+		var unsupported *gsv.UnsupportedKeywordsError
+		Expect(errors.As(err, &unsupported)).To(BeTrue())
+		Expect(unsupported.Keywords).To(ConsistOf(
+			"/properties/a: not",
+			`/properties/b: $ref "https://example.com/b.json": only local references are supported`,
+			"/properties/c: if",
+			"/properties/c: then",
+		))
+	})
+
+	It("reports keywords next to const, enum and the combinators", func() {
+		for schema, keywords := range map[string][]string{
+			`{"type": "object", "properties": {"a": {"type": "string"}}, "anyOf": [{"required": ["a"]}, {"required": ["b"]}]}`: {
+				"/: properties alongside anyOf",
+				"/: type alongside anyOf",
+			},
+			`{"const": "x", "allOf": [{"minLength": 5}]}`:                 {"/: allOf alongside const"},
+			`{"oneOf": [{"type": "integer"}], "allOf": [{"minimum": 3}]}`: {"/: allOf alongside oneOf"},
+			`{"type": "integer", "enum": ["a", 1]}`:                       {"/: type alongside enum"},
+		} {
+			_, err := gsv.FromJSONSchema([]byte(schema))
+
+			var unsupported *gsv.UnsupportedKeywordsError
+			Expect(errors.As(err, &unsupported)).To(BeTrue(), schema)
+			Expect(unsupported.Keywords).To(ConsistOf(keywords), schema)
+		}
+
+		_, err := gsv.FromJSONSchema([]byte(`{"type": ["integer", "null"], "enum": [1, 2, null], "description": "A level"}`))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("reports malformed multipleOf and counts instead of panicking", func() {
+		for schema, keyword := range map[string]string{
+			`{"type": "number", "multipleOf": 0}`:        "/: multipleOf 0",
+			`{"type": "number", "multipleOf": -2}`:       "/: multipleOf -2",
+			`{"type": "array", "minItems": -1}`:          "/: minItems -1",
+			`{"type": "array", "maxItems": -1}`:          "/: maxItems -1",
+			`{"type": "string", "minLength": -1}`:        "/: minLength -1",
+			`{"type": "string", "maxLength": -1}`:        "/: maxLength -1",
+			`{"type": "object", "minProperties": -1}`:    "/: minProperties -1",
+			`{"type": "object", "maxProperties": -1}`:    "/: maxProperties -1",
+			`{"propertyNames": {}, "minProperties": -1}`: "/: minProperties -1",
+		} {
+			var err error
+			Expect(func() { _, err = gsv.FromJSONSchema([]byte(schema)) }).ToNot(Panic(), schema)
+
+			var unsupported *gsv.UnsupportedKeywordsError
+			Expect(errors.As(err, &unsupported)).To(BeTrue(), schema)
+			Expect(unsupported.Keywords).To(ConsistOf(keyword), schema)
+		}
+	})
+
+	It("builds objects without additional properties as empty objects", func() {
+		schema, err := gsv.FromJSONSchema([]byte(`{"type": "object", "additionalProperties": false, "propertyNames": {"maxLength": 3}}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(json.Unmarshal([]byte(`{}`), schema)).To(Succeed())
+		Expect(json.Unmarshal([]byte(`{"a": 1}`), schema)).ToNot(Succeed())
+	})
+
+	It("reports enums without values", func() {
+		_, err := gsv.FromJSONSchema([]byte(`{"enum": []}`))
+		Expect(err).To(MatchError("unsupported JSON schema keywords: /: enum without values"))
+	})
+
+	It("applies the keywords of untyped schemas to their own type only", func() {
+		schema, err := gsv.FromJSONSchema([]byte(`{"properties": {"a": {"type": "string"}}, "required": ["a"], "minLength": 2}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, value := range []string{`{"a": "x"}`, `"ab"`, `5`, `true`, `null`, `[1]`} {
+			Expect(json.Unmarshal([]byte(value), schema)).To(Succeed(), value)
+		}
+		for _, value := range []string{`{}`, `{"a": 1}`, `"a"`} {
+			Expect(json.Unmarshal([]byte(value), schema)).ToNot(Succeed(), value)
+		}
+	})
+
+	It("clamps integer bounds to the int64 range", func() {
+		schema, err := gsv.FromJSONSchema([]byte(`{"type": "integer", "minimum": -1e300, "maximum": 1e300}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal([]byte(`5`), schema)).To(Succeed())
+
+		schema, err = gsv.FromJSONSchema([]byte(`{"type": "integer", "exclusiveMinimum": 1e300}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal([]byte(`5`), schema)).ToNot(Succeed())
+	})
+
+	It("reports recursive references", func() {
+		_, err := gsv.FromJSONSchema([]byte(`{
+			"type": "object",
+			"properties": {"children": {"type": "array", "items": {"$ref": "#"}}}
+		}`))
+		Expect(err).To(MatchError(`unsupported JSON schema keywords: /properties/children/items: recursive $ref "#"`))
+
+		_, err = gsv.FromJSONSchema([]byte(`{
+			"$defs": {
+				"a": {
+					"type": "object",
+					"properties": {"kind": {"const": "a"}, "child": {"$ref": "#/$defs/a"}},
+					"required": ["kind"]
+				},
+				"b": {
+					"type": "object",
+					"properties": {"kind": {"const": "b"}},
+					"required": ["kind"]
+				}
+			},
+			"oneOf": [{"$ref": "#/$defs/a"}, {"$ref": "#/$defs/b"}]
+		}`))
+		Expect(err).To(MatchError(`unsupported JSON schema keywords: /oneOf/0/properties/child: recursive $ref "#/$defs/a"`))
+	})
+})
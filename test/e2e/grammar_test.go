@@ -0,0 +1,129 @@
+package gsv_e2e_test
+
+import (
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompileGrammar", func() {
+	It("compiles a schema struct into a GBNF grammar", func() {
+		grammar, err := gsv.CompileGrammar(&struct {
+			City  *gsv.StringSchema          `json:"city"`
+			Unit  *gsv.EnumSchema[string]    `json:"unit"`
+			Days  *gsv.NumberSchema[int]     `json:"days"`
+			Temp  *gsv.NumberSchema[float64] `json:"temp"`
+			Tags  *gsv.ArraySchema           `json:"tags"`
+			Place *gsv.ObjectSchema          `json:"place"`
+		}{
+			City: gsv.String().Min(1).Max(20),
+			Unit: gsv.Enum("c", "f").Optional(),
+			Days: gsv.Int(),
+			Temp: gsv.Float64().Nullable(),
+			Tags: gsv.Array(gsv.String()).MinItems(1).MaxItems(3),
+			Place: gsv.Object(map[string]gsv.Schema{
+				"lat": gsv.Float64(),
+				"lng": gsv.Float64().Optional(),
+			}),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(grammar)).To(Equal(
+			`root ::= "{" space root-city-kv "," space root-days-kv "," space root-temp-kv "," space root-tags-kv "," space root-place-kv ("," space root-unit-kv)? "}" space
+root-city ::= "\"" char{1,20} "\"" space
+root-city-kv ::= "\"city\"" space ":" space root-city
+root-days-kv ::= "\"days\"" space ":" space integer
+root-temp ::= number | null
+root-temp-kv ::= "\"temp\"" space ":" space root-temp
+root-tags ::= "[" space string ("," space string){0,2} "]" space
+root-tags-kv ::= "\"tags\"" space ":" space root-tags
+root-place-lat-kv ::= "\"lat\"" space ":" space number
+root-place-lng-kv ::= "\"lng\"" space ":" space number
+root-place ::= "{" space root-place-lat-kv ("," space root-place-lng-kv)? "}" space
+root-place-kv ::= "\"place\"" space ":" space root-place
+root-unit ::= "\"c\"" space | "\"f\"" space
+root-unit-kv ::= "\"unit\"" space ":" space root-unit
+char ::= [^"\\\x7F\x00-\x1F] | [\\] (["\\bfnrt] | "u" [0-9a-fA-F]{4})
+decimal-part ::= [0-9]{1,16}
+integer ::= ("-"? integral-part) space
+integral-part ::= [0] | [1-9] [0-9]{0,15}
+null ::= "null" space
+number ::= ("-"? integral-part) ("." decimal-part)? ([eE] [-+]? integral-part)? space
+space ::= | " " | "\n"{1,2} [ \t]{0,20}
+string ::= "\"" char* "\"" space
+`))
+	})
+
+	It("lets any optional property come first", func() {
+		grammar, err := gsv.CompileGrammar(gsv.Object(map[string]gsv.Schema{
+			"a": gsv.Bool().Optional(),
+			"b": gsv.Bool().Optional(),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(grammar)).To(ContainSubstring(
+			`root ::= "{" space (root-a-kv ("," space root-b-kv)? | root-b-kv)? "}" space`))
+	})
+
+	It("compiles array bounds", func() {
+		for schema, rule := range map[*gsv.ArraySchema]string{
+			gsv.Array(gsv.Int()):                         `root ::= "[" space (integer ("," space integer)*)? "]" space`,
+			gsv.Array(gsv.Int()).MinItems(2):             `root ::= "[" space integer ("," space integer)+ "]" space`,
+			gsv.Array(gsv.Int()).MaxItems(1):             `root ::= "[" space (integer)? "]" space`,
+			gsv.Array(gsv.Int()).MinItems(2).MaxItems(2): `root ::= "[" space integer ("," space integer){1} "]" space`,
+		} {
+			grammar, err := gsv.CompileGrammar(schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(grammar)).To(HavePrefix(rule + "\n"))
+		}
+	})
+
+	It("compiles unions, records and literals", func() {
+		grammar, err := gsv.CompileGrammar(&struct {
+			Value   *gsv.UnionSchema        `json:"value"`
+			Headers *gsv.RecordSchema       `json:"headers"`
+			Kind    *gsv.EnumSchema[string] `json:"kind"`
+		}{
+			Value:   gsv.Union(gsv.String(), gsv.Bool()),
+			Headers: gsv.Record(gsv.String(), gsv.String()),
+			Kind:    gsv.Literal(`say "hi"`),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(grammar)).To(ContainSubstring("root-value ::= string | boolean\n"))
+		Expect(string(grammar)).To(ContainSubstring(
+			`root-headers ::= "{" space (string ":" space string ("," space string ":" space string)*)? "}" space` + "\n"))
+		Expect(string(grammar)).To(ContainSubstring(`root-kind ::= "\"say \\\"hi\\\"\"" space` + "\n"))
+	})
+
+	It("lists the constructs left to Parse", func() {
+		grammar, err := gsv.CompileGrammar(&struct {
+			Age   *gsv.NumberSchema[int] `json:"age"`
+			Email *gsv.StringSchema      `json:"email"`
+		}{
+			Age:   gsv.Int().Min(0).Max(150),
+			Email: gsv.String().Email(),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(grammar)).To(HavePrefix("# Checked by Parse, not by the grammar:\n" +
+			"#   /properties/age: numeric ranges\n" +
+			"#   /properties/email: format\n\n" +
+			"root ::= "))
+	})
+
+	It("lists the lengths of strings not counted in code points", func() {
+		grammar, err := gsv.CompileGrammar(&struct {
+			Name *gsv.StringSchema `json:"name"`
+			Tags *gsv.ArraySchema  `json:"tags"`
+		}{
+			Name: gsv.String().Max(10),
+			Tags: gsv.Array(gsv.Union(gsv.String().Bytes().Max(8), gsv.String().Graphemes().Min(1))),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(grammar)).To(HavePrefix("# Checked by Parse, not by the grammar:\n" +
+			"#   /properties/tags/items/anyOf/0: length unit\n" +
+			"#   /properties/tags/items/anyOf/1: length unit\n\n" +
+			"root ::= "))
+	})
+})
@@ -0,0 +1,226 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	"github.com/agent-api/gsv/pkg/jsonschema"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSONSchema validation", func() {
+	parse := func(schema string) *jsonschema.JSONSchema {
+		var s jsonschema.JSONSchema
+		Expect(json.Unmarshal([]byte(schema), &s)).To(Succeed())
+		return &s
+	}
+
+	It("returns the basic output format", func() {
+		schema := parse(`{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"age": {"type": "integer", "minimum": 0},
+				"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true}
+			},
+			"required": ["name", "age"],
+			"additionalProperties": false
+		}`)
+
+		output, err := schema.ValidateJSON([]byte(`{"name": "", "tags": ["a", 1, "a"], "extra": true}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := json.Marshal(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"valid": false,
+			"errors": [
+				{"keywordLocation": "/required", "instanceLocation": "", "error": "missing required property \"age\""},
+				{"keywordLocation": "/additionalProperties", "instanceLocation": "/extra", "error": "property \"extra\" is not allowed"},
+				{"keywordLocation": "/properties/name/minLength", "instanceLocation": "/name", "error": "must be at least 1 characters long"},
+				{"keywordLocation": "/properties/tags/uniqueItems", "instanceLocation": "/tags", "error": "must not have duplicate items, items 0 and 2 are equal"},
+				{"keywordLocation": "/properties/tags/items/type", "instanceLocation": "/tags/1", "error": "must be of type string, got number"}
+			]
+		}`))
+
+		output, err = schema.ValidateJSON([]byte(`{"name": "Ada", "age": 36, "tags": ["a"]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Valid).To(BeTrue())
+		Expect(output.Errors).To(BeEmpty())
+	})
+
+	It("validates string, number and array constraints", func() {
+		schema := parse(`{
+			"type": "object",
+			"properties": {
+				"code": {"type": "string", "maxLength": 3, "pattern": "^[A-Z]+$"},
+				"price": {"type": "number", "exclusiveMinimum": 0, "maximum": 100, "multipleOf": 0.01},
+				"count": {"type": "integer"},
+				"items": {"type": "array", "minItems": 1, "maxItems": 2}
+			}
+		}`)
+
+		output, err := schema.ValidateJSON([]byte(`{"code": "éé", "price": 0, "count": 1.0, "items": []}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(
+			jsonschema.OutputUnit{KeywordLocation: "/properties/code/pattern", InstanceLocation: "/code", Error: "must match pattern ^[A-Z]+$"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/price/exclusiveMinimum", InstanceLocation: "/price", Error: "must be greater than 0"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/items/minItems", InstanceLocation: "/items", Error: "must have at least 1 items"},
+		))
+
+		output, err = schema.ValidateJSON([]byte(`{"code": "ABCD", "price": 10.005, "count": 1.5, "items": [1, 2, 3]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(
+			jsonschema.OutputUnit{KeywordLocation: "/properties/code/maxLength", InstanceLocation: "/code", Error: "must be at most 3 characters long"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/price/multipleOf", InstanceLocation: "/price", Error: "must be a multiple of 0.01"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/count/type", InstanceLocation: "/count", Error: "must be of type integer, got number"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/items/maxItems", InstanceLocation: "/items", Error: "must have at most 2 items"},
+		))
+	})
+
+	It("validates object keywords", func() {
+		schema := parse(`{
+			"type": "object",
+			"properties": {"id": {"type": "string"}},
+			"patternProperties": {"^x-": {"type": "string"}},
+			"additionalProperties": {"type": "number"},
+			"propertyNames": {"maxLength": 5},
+			"minProperties": 2,
+			"maxProperties": 3
+		}`)
+
+		output, err := schema.ValidateJSON([]byte(`{"id": "a", "x-a": "b", "n": 1}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Valid).To(BeTrue())
+
+		output, err = schema.ValidateJSON([]byte(`{"x-a": 1, "n": "b", "toolong": 1, "id": "a"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(
+			jsonschema.OutputUnit{KeywordLocation: "/maxProperties", InstanceLocation: "", Error: "must have at most 3 properties"},
+			jsonschema.OutputUnit{KeywordLocation: "/patternProperties/^x-/type", InstanceLocation: "/x-a", Error: "must be of type string, got number"},
+			jsonschema.OutputUnit{KeywordLocation: "/additionalProperties/type", InstanceLocation: "/n", Error: "must be of type number, got string"},
+			jsonschema.OutputUnit{KeywordLocation: "/propertyNames/maxLength", InstanceLocation: "/toolong", Error: "must be at most 5 characters long"},
+		))
+
+		output, err = schema.ValidateJSON([]byte(`{"id": "a"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(
+			jsonschema.OutputUnit{KeywordLocation: "/minProperties", InstanceLocation: "", Error: "must have at least 2 properties"},
+		))
+	})
+
+	It("validates enum, const and the combinators", func() {
+		schema := parse(`{
+			"type": "object",
+			"properties": {
+				"unit": {"enum": ["c", "f", null]},
+				"version": {"const": 2},
+				"id": {"anyOf": [{"type": "string"}, {"type": "integer"}]},
+				"size": {"oneOf": [{"type": "integer"}, {"type": "number", "minimum": 10}]},
+				"name": {"allOf": [{"minLength": 2}, {"pattern": "^a"}]},
+				"status": {"not": {"const": "deleted"}}
+			}
+		}`)
+
+		output, err := schema.ValidateJSON([]byte(`{"unit": null, "version": 2.0, "id": 7, "size": 1, "name": "ab", "status": "ok"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Valid).To(BeTrue())
+
+		output, err = schema.ValidateJSON([]byte(`{"unit": "k", "version": 1, "id": true, "size": 12, "name": "b", "status": "deleted"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(
+			jsonschema.OutputUnit{KeywordLocation: "/properties/unit/enum", InstanceLocation: "/unit", Error: `must be one of ["c","f",null]`},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/version/const", InstanceLocation: "/version", Error: "must be 2"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/id/anyOf", InstanceLocation: "/id", Error: "must match at least one schema"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/id/anyOf/0/type", InstanceLocation: "/id", Error: "must be of type string, got boolean"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/id/anyOf/1/type", InstanceLocation: "/id", Error: "must be of type integer, got boolean"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/size/oneOf", InstanceLocation: "/size", Error: "must match exactly one schema, matched schemas 0, 1"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/name/allOf/0/minLength", InstanceLocation: "/name", Error: "must be at least 2 characters long"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/name/allOf/1/pattern", InstanceLocation: "/name", Error: "must match pattern ^a"},
+			jsonschema.OutputUnit{KeywordLocation: "/properties/status/not", InstanceLocation: "/status", Error: "must not match the schema"},
+		))
+	})
+
+	It("tells a const of null apart from no const", func() {
+		schema := parse(`{"const": null}`)
+
+		output, err := schema.Validate(5)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(jsonschema.OutputUnit{KeywordLocation: "/const", Error: "must be null"}))
+
+		output, err = schema.Validate(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Valid).To(BeTrue())
+
+		data, err := json.Marshal(schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{"const": null}`))
+		Expect(json.Marshal(parse(`{}`))).To(MatchJSON(`{}`))
+
+		built, err := gsv.FromJSONSchema([]byte(`{"const": null}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal([]byte(`null`), built)).To(Succeed())
+		Expect(json.Unmarshal([]byte(`5`), built)).ToNot(Succeed())
+	})
+
+	It("follows local references and boolean schemas", func() {
+		schema := parse(`{
+			"$defs": {
+				"node": {
+					"type": "object",
+					"properties": {
+						"value": {"type": "integer"},
+						"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}
+					},
+					"required": ["value"]
+				}
+			},
+			"$ref": "#/$defs/node"
+		}`)
+
+		output, err := schema.ValidateJSON([]byte(`{"value": 1, "children": [{"value": 2, "children": [{}]}]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(jsonschema.OutputUnit{
+			KeywordLocation:  "/$ref/properties/children/items/$ref/properties/children/items/$ref/required",
+			InstanceLocation: "/children/0/children/0",
+			Error:            `missing required property "value"`,
+		}))
+
+		output, err = jsonschema.Bool(false).Validate("anything")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(jsonschema.OutputUnit{Error: "no value is allowed"}))
+	})
+
+	It("fails on schemas it can't use", func() {
+		_, err := parse(`{"$ref": "#/$defs/missing"}`).ValidateJSON([]byte(`1`))
+		Expect(err).To(MatchError(`could not resolve $ref "#/$defs/missing" at /$ref: no schema at /$defs/missing`))
+
+		_, err = parse(`{"$ref": "#"}`).ValidateJSON([]byte(`1`))
+		Expect(err).To(MatchError("$ref # at /$ref/$ref loops without validating a nested value"))
+
+		_, err = parse(`{"pattern": "("}`).ValidateJSON([]byte(`"a"`))
+		Expect(err).To(MatchError(ContainSubstring("invalid pattern at /pattern")))
+	})
+
+	It("validates values against the schemas CompileSchema writes", func() {
+		compiled, err := gsv.CompileSchema(&struct {
+			City *gsv.StringSchema      `json:"city"`
+			Days *gsv.NumberSchema[int] `json:"days"`
+		}{
+			City: gsv.String().Min(1),
+			Days: gsv.Int().Max(7).Nullable(),
+		}, &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		schema := parse(string(compiled))
+
+		output, err := schema.ValidateJSON([]byte(`{"city": "Paris", "days": null}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Valid).To(BeTrue())
+
+		output, err = schema.ValidateJSON([]byte(`{"city": "", "days": 8}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Errors).To(HaveLen(2))
+	})
+})
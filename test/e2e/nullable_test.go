@@ -0,0 +1,172 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	"github.com/agent-api/gsv/pkg/jsonschema"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Nullable", func() {
+	type TestProfileSchema struct {
+		Name     *gsv.StringSchema      `json:"name"`
+		Nickname *gsv.StringSchema      `json:"nickname"`
+		Age      *gsv.NumberSchema[int] `json:"age"`
+		Tags     *gsv.ArraySchema       `json:"tags"`
+	}
+
+	newSchema := func() *TestProfileSchema {
+		return &TestProfileSchema{
+			Name:     gsv.String(),
+			Nickname: gsv.String().Min(2).Nullable(),
+			Age:      gsv.Int().Nullable().Optional(),
+			Tags:     gsv.Array(gsv.String().Nullable()).Optional(),
+		}
+	}
+
+	It("accepts null values", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"name": "Jane", "nickname": null, "age": null, "tags": ["a", null]}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.HasErrors()).To(BeFalse())
+
+		Expect(schema.Nickname.State()).To(Equal(gsv.Null))
+		_, ok := schema.Nickname.Value()
+		Expect(ok).To(BeFalse())
+
+		Expect(schema.Age.State()).To(Equal(gsv.Null))
+
+		tags, _ := schema.Tags.Value()
+		Expect(tags).To(Equal([]interface{}{"a", nil}))
+	})
+
+	It("still requires the key of nullable fields", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"name": "Jane"}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.RequiredStringError))
+		Expect(result.Errors[0].Field).To(Equal("/nickname"))
+
+		Expect(schema.Nickname.State()).To(Equal(gsv.Absent))
+		Expect(schema.Age.State()).To(Equal(gsv.Absent))
+	})
+
+	It("reports values as present", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"name": "Jane", "nickname": "J"}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+
+		Expect(schema.Nickname.State()).To(Equal(gsv.Present))
+	})
+
+	It("reports null for schemas that aren't nullable", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"name": null, "nickname": null}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.NullValueError))
+		Expect(result.Errors[0].Field).To(Equal("/name"))
+
+		Expect(schema.Name.State()).To(Equal(gsv.Null))
+	})
+
+	It("reports null for optional fields that aren't nullable", func() {
+		schema := &struct {
+			Score *gsv.NumberSchema[float64] `json:"score"`
+		}{
+			Score: gsv.Float64().Optional(),
+		}
+
+		result, err := gsv.Parse([]byte(`{}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.HasErrors()).To(BeFalse())
+
+		result, err = gsv.Parse([]byte(`{"score": null}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.NullValueError))
+		Expect(result.Errors[0].Field).To(Equal("/score"))
+
+		built, err := gsv.FromJSONSchema([]byte(`{"properties": {"a": {"type": "string"}}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal([]byte(`{}`), built)).To(Succeed())
+		Expect(json.Unmarshal([]byte(`{"a": null}`), built)).ToNot(Succeed())
+	})
+
+	It("keeps null in objects", func() {
+		schema := gsv.Object(map[string]gsv.Schema{
+			"note": gsv.String().Nullable(),
+		})
+
+		Expect(json.Unmarshal([]byte(`{"note": null}`), schema)).To(Succeed())
+
+		val, _ := schema.Value()
+		Expect(val).To(HaveKeyWithValue("note", BeNil()))
+
+		data, err := json.Marshal(schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{"note": null}`))
+
+		Expect(schema.Set(map[string]interface{}{"note": nil}).Validate().HasErrors()).To(BeFalse())
+		Expect(schema.Field("note").State()).To(Equal(gsv.Null))
+	})
+
+	It("doesn't replace null with the default", func() {
+		schema := gsv.Int().Nullable().Default(1)
+
+		Expect(json.Unmarshal([]byte(`null`), schema)).To(Succeed())
+		Expect(schema.State()).To(Equal(gsv.Null))
+	})
+
+	It("compiles to type arrays", func() {
+		profile := newSchema()
+		compiled, err := gsv.CompileSchema(&struct {
+			Name     *gsv.StringSchema       `json:"name"`
+			Nickname *gsv.StringSchema       `json:"nickname"`
+			Age      *gsv.NumberSchema[int]  `json:"age"`
+			Tags     *gsv.ArraySchema        `json:"tags"`
+			Status   *gsv.EnumSchema[string] `json:"status"`
+			Value    *gsv.UnionSchema        `json:"value"`
+		}{
+			Name:     profile.Name,
+			Nickname: profile.Nickname,
+			Age:      profile.Age,
+			Tags:     profile.Tags,
+			Status:   gsv.Enum("on", "off").Nullable(),
+			Value:    gsv.Union(gsv.String(), gsv.Int()).Nullable(),
+		}, &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"nickname": {"type": ["string", "null"], "minLength": 2},
+				"age": {"type": ["integer", "null"]},
+				"tags": {"type": "array", "items": {"type": ["string", "null"]}},
+				"status": {"type": ["string", "null"], "enum": ["on", "off", null]},
+				"value": {"anyOf": [{"type": "string"}, {"type": "integer"}, {"type": "null"}]}
+			},
+			"required": ["name", "nickname", "status", "value"]
+		}`))
+	})
+
+	It("unmarshals type arrays", func() {
+		var schema jsonschema.JSONSchema
+		Expect(json.Unmarshal([]byte(`{"type": ["string", "null"]}`), &schema)).To(Succeed())
+		Expect(schema.Type).To(Equal(jsonschema.Types("string", "null")))
+		Expect(schema.Type.Has("null")).To(BeTrue())
+
+		Expect(json.Unmarshal([]byte(`{"type": "string"}`), &schema)).To(Succeed())
+		Expect(schema.Type).To(Equal(jsonschema.Types("string")))
+	})
+})
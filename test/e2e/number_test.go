@@ -0,0 +1,134 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NumberSchema constraints", func() {
+	Describe("Exclusive bounds", func() {
+		DescribeTable("validates Gt and Lt",
+			func(value int, errorType gsv.ValidationErrorType) {
+				v := gsv.Int().Gt(0).Lt(10)
+				v.Set(value)
+				result := v.Validate()
+
+				if errorType == "" {
+					Expect(result.HasErrors()).To(BeFalse())
+				} else {
+					Expect(result.Errors).To(HaveLen(1))
+					Expect(result.Errors[0].Type).To(Equal(errorType))
+				}
+			},
+			Entry("within bounds", 5, gsv.ValidationErrorType("")),
+			Entry("equal to the lower bound", 0, gsv.ExclusiveMinNumberError),
+			Entry("equal to the upper bound", 10, gsv.ExclusiveMaxNumberError),
+		)
+
+		It("supports the sign helpers", func() {
+			Expect(gsv.Int().Positive().Set(0).Validate().HasErrors()).To(BeTrue())
+			Expect(gsv.Int().NonNegative().Set(0).Validate().HasErrors()).To(BeFalse())
+			Expect(gsv.Int().Negative().Set(0).Validate().HasErrors()).To(BeTrue())
+			Expect(gsv.Int().NonPositive().Set(0).Validate().HasErrors()).To(BeFalse())
+		})
+	})
+
+	Describe("MultipleOf", func() {
+		DescribeTable("validates integer multiples",
+			func(value int, expectError bool) {
+				result := gsv.Int().MultipleOf(5).Set(value).Validate()
+				Expect(result.HasErrors()).To(Equal(expectError))
+			},
+			Entry("multiple", 15, false),
+			Entry("negative multiple", -10, false),
+			Entry("not a multiple", 7, true),
+		)
+
+		DescribeTable("validates float multiples",
+			func(value float64, expectError bool) {
+				result := gsv.Float64().MultipleOf(0.1).Set(value).Validate()
+				Expect(result.HasErrors()).To(Equal(expectError))
+			},
+			Entry("multiple with rounding error", 0.3, false),
+			Entry("not a multiple", 0.35, true),
+		)
+
+		It("panics on values that aren't positive", func() {
+			Expect(func() { gsv.Int().MultipleOf(0) }).To(Panic())
+		})
+	})
+
+	Describe("Int and Safe", func() {
+		It("requires whole numbers", func() {
+			result := gsv.Float64().Int().Set(1.5).Validate()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.IntegerNumberError))
+
+			Expect(gsv.Float64().Int().Set(2).Validate().HasErrors()).To(BeFalse())
+		})
+
+		It("requires safe integers", func() {
+			result := gsv.Int64().Safe().Set(gsv.MaxSafeInteger + 1).Validate()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.UnsafeNumberError))
+
+			Expect(gsv.Int64().Safe().Set(gsv.MaxSafeInteger).Validate().HasErrors()).To(BeFalse())
+		})
+
+		It("only accepts them for numeric types", func() {
+			// NumberSchema still takes any cmp.Ordered type
+			Expect(gsv.Number[string]().Min("b").Set("c").Validate().HasErrors()).To(BeFalse())
+
+			Expect(func() { gsv.Number[string]().Int() }).To(Panic())
+			Expect(func() { gsv.Number[string]().MultipleOf("a") }).To(Panic())
+		})
+	})
+
+	Describe("JSON Schema compiling", func() {
+		type NumberConstraintsSchema struct {
+			Bounded   *gsv.IntSchema     `json:"bounded"`
+			Exclusive *gsv.Float64Schema `json:"exclusive"`
+			Step      *gsv.Float64Schema `json:"step"`
+			Safe      *gsv.Int64Schema   `json:"safe"`
+		}
+
+		It("emits every constraint", func() {
+			schema := NumberConstraintsSchema{
+				Bounded:   gsv.Int().Min(1).Max(10),
+				Exclusive: gsv.Float64().Positive().Lt(1),
+				Step:      gsv.Float64().MultipleOf(0.5).Int(),
+				Safe:      gsv.Int64().Safe().Min(0),
+			}
+
+			result, err := gsv.CompileSchema(schema, &gsv.CompileSchemaOpts{})
+			Expect(err).ToNot(HaveOccurred())
+
+			var jsonSchema map[string]interface{}
+			Expect(json.Unmarshal(result, &jsonSchema)).To(Succeed())
+			properties := jsonSchema["properties"].(map[string]interface{})
+
+			Expect(properties["bounded"]).To(Equal(map[string]interface{}{
+				"type":    "integer",
+				"minimum": float64(1),
+				"maximum": float64(10),
+			}))
+			Expect(properties["exclusive"]).To(Equal(map[string]interface{}{
+				"type":             "number",
+				"exclusiveMinimum": float64(0),
+				"exclusiveMaximum": float64(1),
+			}))
+			Expect(properties["step"]).To(Equal(map[string]interface{}{
+				"type":       "integer",
+				"multipleOf": 0.5,
+			}))
+			Expect(properties["safe"]).To(Equal(map[string]interface{}{
+				"type":    "integer",
+				"minimum": float64(0),
+				"maximum": float64(gsv.MaxSafeInteger),
+			}))
+		})
+	})
+})
@@ -0,0 +1,180 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	"github.com/agent-api/gsv/pkg/jsonschema"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ObjectSchema", func() {
+	Describe("Implements Schema interface", func() {
+		// Compile time check for ObjectSchema implementing the Schema interface
+		schema := gsv.Object(map[string]gsv.Schema{})
+		var _ gsv.Schema = schema
+	})
+
+	newUser := func() *gsv.ObjectSchema {
+		return gsv.Object(map[string]gsv.Schema{
+			"name": gsv.String().Min(3),
+			"age":  gsv.Int().Optional(),
+		})
+	}
+
+	It("panics when a shape schema is nil", func() {
+		Expect(func() {
+			gsv.Object(map[string]gsv.Schema{"name": nil})
+		}).To(Panic())
+	})
+
+	Describe("JSON Unmarshaling", func() {
+		It("decodes and validates each key of the shape", func() {
+			schema := newUser()
+
+			err := json.Unmarshal([]byte(`{"name": "John", "age": 30}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+
+			val, ok := schema.Value()
+			Expect(ok).To(BeTrue())
+			Expect(val).To(Equal(map[string]interface{}{"name": "John", "age": 30}))
+
+			name, ok := schema.Field("name").(*gsv.StringSchema).Value()
+			Expect(ok).To(BeTrue())
+			Expect(name).To(Equal("John"))
+		})
+
+		It("reports errors at the key of the failing schema", func() {
+			schema := newUser()
+
+			result, err := gsv.Parse([]byte(`{"name": "Jo"}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+			Expect(result.Errors[0].Field).To(Equal("/name"))
+		})
+
+		It("reports a missing required object", func() {
+			schema := newUser()
+
+			result := schema.Validate()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.RequiredObjectError))
+		})
+	})
+
+	Describe("Unknown keys", func() {
+		const payload = `{"name": "John", "nmae": "Jon"}`
+
+		It("strips unknown keys by default", func() {
+			schema := newUser()
+
+			result, err := gsv.Parse([]byte(payload), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+
+			val, _ := schema.Value()
+			Expect(val).ToNot(HaveKey("nmae"))
+		})
+
+		It("rejects unknown keys in strict mode", func() {
+			schema := newUser().Strict()
+
+			result, err := gsv.Parse([]byte(payload), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.UnknownObjectKeyError))
+			Expect(result.Errors[0].Field).To(Equal("/nmae"))
+		})
+
+		It("keeps unknown keys in passthrough mode", func() {
+			schema := newUser().Passthrough()
+
+			result, err := gsv.Parse([]byte(payload), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+
+			val, _ := schema.Value()
+			Expect(val).To(HaveKeyWithValue("nmae", "Jon"))
+
+			data, err := json.Marshal(schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(MatchJSON(payload))
+		})
+
+		It("validates unknown keys against the catchall schema", func() {
+			schema := newUser().Catchall(gsv.String().Min(4))
+
+			result, err := gsv.Parse([]byte(payload), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+			Expect(result.Errors[0].Field).To(Equal("/nmae"))
+		})
+	})
+
+	Describe("Array elements", func() {
+		It("validates objects as array elements", func() {
+			schema := gsv.Array(newUser().Strict())
+
+			err := json.Unmarshal([]byte(`[{"name": "John"}, {"name": "Jo", "x": 1}]`), schema)
+			Expect(err).To(HaveOccurred())
+
+			result := schema.Validate()
+			Expect(result.Errors).To(HaveLen(2))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+			Expect(result.Errors[1].Type).To(Equal(gsv.UnknownObjectKeyError))
+		})
+	})
+
+	Describe("Clone functionality", func() {
+		It("creates an independent copy of the shape", func() {
+			original := newUser()
+			Expect(json.Unmarshal([]byte(`{"name": "John"}`), original)).To(Succeed())
+
+			cloned := original.Clone().(*gsv.ObjectSchema)
+			Expect(json.Unmarshal([]byte(`{"name": "Jane"}`), original)).To(Succeed())
+
+			val, ok := cloned.Value()
+			Expect(ok).To(BeTrue())
+			Expect(val).To(HaveKeyWithValue("name", "John"))
+		})
+	})
+
+	Describe("JSON Schema compiling", func() {
+		compile := func(schema gsv.Schema) map[string]interface{} {
+			root := &jsonschema.JSONSchema{
+				Properties: make(map[string]*jsonschema.JSONSchema),
+			}
+			Expect(gsv.Array(schema).CompileJSONSchema(root, "users")).To(Succeed())
+
+			data, err := json.Marshal(root.Properties["users"].Items)
+			Expect(err).ToNot(HaveOccurred())
+
+			items := map[string]interface{}{}
+			Expect(json.Unmarshal(data, &items)).To(Succeed())
+			return items
+		}
+
+		It("compiles the shape into properties", func() {
+			items := compile(newUser())
+
+			Expect(items["type"]).To(Equal("object"))
+			Expect(items["properties"]).To(HaveKey("name"))
+			Expect(items["properties"]).To(HaveKey("age"))
+			Expect(items["required"]).To(Equal([]interface{}{"name"}))
+			Expect(items).ToNot(HaveKey("additionalProperties"))
+		})
+
+		It("emits additionalProperties false in strict mode", func() {
+			items := compile(newUser().Strict())
+			Expect(items["additionalProperties"]).To(Equal(false))
+		})
+
+		It("emits the catchall schema as additionalProperties", func() {
+			items := compile(newUser().Catchall(gsv.String()))
+			Expect(items["additionalProperties"]).To(Equal(map[string]interface{}{"type": "string"}))
+		})
+	})
+})
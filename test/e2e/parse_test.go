@@ -0,0 +1,185 @@
+package gsv_e2e_test
+
+import (
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	type TestParseSchema struct {
+		Name  *gsv.StringSchema `json:"name"`
+		Title *gsv.StringSchema `json:"title"`
+		Score *gsv.IntSchema    `json:"score"`
+	}
+
+	newSchema := func() *TestParseSchema {
+		return &TestParseSchema{
+			Name:  gsv.String().Min(5).Max(3),
+			Title: gsv.String(),
+			Score: gsv.Int().Min(0),
+		}
+	}
+
+	It("collects every validation error by default", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"name": "abcd", "score": -1}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(4))
+		Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+		Expect(result.Errors[1].Type).To(Equal(gsv.MaxStringLengthError))
+		Expect(result.Errors[2].Type).To(Equal(gsv.RequiredStringError))
+		Expect(result.Errors[3].Type).To(Equal(gsv.MinNumberError))
+
+		val, ok := schema.Score.Value()
+		Expect(ok).To(BeTrue())
+		Expect(val).To(Equal(-1))
+	})
+
+	It("returns an error for values of the wrong type", func() {
+		schema := newSchema()
+
+		_, err := gsv.Parse([]byte(`{"name": 1}`), schema)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid string value"))
+	})
+
+	Context("with StopOnFirst", func() {
+		It("stops at the first failing validator", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{"name": "abcd", "score": -1}`), schema, gsv.ParseOptions{
+				StopOnFirst: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+		})
+	})
+
+	Context("with SkipMissing", func() {
+		It("skips required errors for fields absent from the payload", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{"score": 10}`), schema, gsv.ParseOptions{
+				SkipMissing: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+		})
+
+		It("still validates fields that are present", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{"score": -1}`), schema, gsv.ParseOptions{
+				SkipMissing: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MinNumberError))
+		})
+	})
+
+	Context("with Strict", func() {
+		type TestStrictNestedSchema struct {
+			Score *gsv.IntSchema `json:"score"`
+		}
+
+		type TestStrictSchema struct {
+			Name   *gsv.StringSchema       `json:"name"`
+			Nested *TestStrictNestedSchema `json:"nested"`
+			Items  *gsv.ArraySchema        `json:"items"`
+		}
+
+		newStrictSchema := func() *TestStrictSchema {
+			return &TestStrictSchema{
+				Name:   gsv.String(),
+				Nested: &TestStrictNestedSchema{Score: gsv.Int()},
+				Items: gsv.Array(gsv.Object(map[string]gsv.Schema{
+					"label": gsv.String(),
+				})),
+			}
+		}
+
+		It("reports unknown keys with the closest known key", func() {
+			schema := newStrictSchema()
+
+			result, err := gsv.Parse([]byte(`{"nmae": "x", "nested": {"score": 1}, "items": []}`), schema, gsv.ParseOptions{
+				Strict: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(2))
+
+			Expect(result.Errors[0].Type).To(Equal(gsv.UnknownObjectKeyError))
+			Expect(result.Errors[0].Message).To(Equal(`unknown key "nmae", did you mean "name"?`))
+			Expect(result.Errors[0].Expected).To(Equal("name"))
+			Expect(result.Errors[0].Actual).To(Equal("nmae"))
+
+			Expect(result.Errors[1].Type).To(Equal(gsv.RequiredStringError))
+		})
+
+		It("reports unknown keys of nested structs, arrays and objects", func() {
+			schema := newStrictSchema()
+
+			result, err := gsv.Parse([]byte(`{
+				"name": "x",
+				"nested": {"score": 1, "scroe": 2},
+				"items": [{"label": "a"}, {"label": "b", "zzz": true}]
+			}`), schema, gsv.ParseOptions{
+				Strict: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(2))
+			Expect(result.Errors[0].Type).To(Equal(gsv.UnknownObjectKeyError))
+			Expect(result.Errors[0].Field).To(Equal("/items/1/zzz"))
+			Expect(result.Errors[0].Message).To(Equal(`unknown key "zzz"`))
+			Expect(result.Errors[0].Expected).To(BeNil())
+			Expect(result.Errors[1].Type).To(Equal(gsv.UnknownObjectKeyError))
+			Expect(result.Errors[1].Field).To(Equal("/nested/scroe"))
+			Expect(result.Errors[1].Expected).To(Equal("score"))
+		})
+
+		It("ignores unknown keys without Strict", func() {
+			schema := newStrictSchema()
+
+			result, err := gsv.Parse([]byte(`{"name": "x", "nmae": "x", "nested": {"score": 1}, "items": []}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+		})
+	})
+
+	Context("with an ErrorMode", func() {
+		It("returns only the first error with ReturnFirstError", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{"name": "abcd", "score": -1}`), schema, gsv.ParseOptions{
+				ErrorMode: gsv.ReturnFirstError,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+		})
+
+		It("panics with PanicOnError", func() {
+			schema := newSchema()
+
+			Expect(func() {
+				_, _ = gsv.Parse([]byte(`{"name": "abcd"}`), schema, gsv.ParseOptions{
+					ErrorMode: gsv.PanicOnError,
+				})
+			}).To(Panic())
+		})
+
+		It("does not panic with PanicOnError when the payload is valid", func() {
+			schema := newSchema()
+			schema.Name = gsv.String()
+
+			Expect(func() {
+				_, _ = gsv.Parse([]byte(`{"name": "abcd", "title": "t", "score": 1}`), schema, gsv.ParseOptions{
+					ErrorMode: gsv.PanicOnError,
+				})
+			}).ToNot(Panic())
+		})
+	})
+})
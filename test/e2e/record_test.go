@@ -0,0 +1,91 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RecordSchema", func() {
+	type TestRequestSchema struct {
+		Headers *gsv.RecordSchema `json:"headers"`
+	}
+
+	It("decodes dynamic keys", func() {
+		schema := &TestRequestSchema{Headers: gsv.Record(gsv.String(), gsv.String())}
+
+		result, err := gsv.Parse([]byte(`{"headers": {"X-Foo": "bar", "Accept": "*/*"}}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.HasErrors()).To(BeFalse())
+
+		val, ok := schema.Headers.Value()
+		Expect(ok).To(BeTrue())
+		Expect(val).To(Equal(map[string]interface{}{"X-Foo": "bar", "Accept": "*/*"}))
+	})
+
+	It("validates every value at its key", func() {
+		schema := &TestRequestSchema{Headers: gsv.Record(gsv.String(), gsv.String().Min(2))}
+
+		result, err := gsv.Parse([]byte(`{"headers": {"a": "ok", "b": "x"}}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+		Expect(result.Errors[0].Field).To(Equal("/headers/b"))
+	})
+
+	It("validates keys against the key schema and pattern", func() {
+		schema := gsv.Record(gsv.String().Max(5), gsv.Int()).KeyPattern(`^[a-z]+$`)
+
+		Expect(json.Unmarshal([]byte(`{"abc": 1, "Abcdef": 2}`), schema)).ToNot(Succeed())
+
+		result := schema.Validate()
+		Expect(result.Errors).To(HaveLen(2))
+		Expect(result.Errors[0].Type).To(Equal(gsv.InvalidRecordKeyError))
+		Expect(result.Errors[0].Field).To(Equal("/Abcdef"))
+		Expect(result.Errors[0].Expected).To(Equal(`^[a-z]+$`))
+		Expect(result.Errors[1].Type).To(Equal(gsv.MaxStringLengthError))
+		Expect(result.Errors[1].Field).To(Equal("/Abcdef"))
+	})
+
+	It("validates the number of properties", func() {
+		schema := gsv.Record(gsv.String(), gsv.Int()).MinProperties(2).MaxProperties(3)
+
+		result := schema.Set(map[string]interface{}{"a": 1}).Validate()
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.MinPropertiesError))
+		Expect(result.Errors[0].Expected).To(Equal(2))
+		Expect(result.Errors[0].Actual).To(Equal(1))
+
+		result = schema.Set(map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4}).Validate()
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.MaxPropertiesError))
+	})
+
+	Context("when compiling", func() {
+		It("emits additionalProperties and the property counts", func() {
+			compiled, err := gsv.CompileSchema(gsv.Record(gsv.String(), gsv.String()).MinProperties(1).MaxProperties(10), &gsv.CompileSchemaOpts{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(compiled)).To(MatchJSON(`{
+				"type": "object",
+				"additionalProperties": {"type": "string"},
+				"minProperties": 1,
+				"maxProperties": 10
+			}`))
+		})
+
+		It("emits patternProperties and propertyNames", func() {
+			compiled, err := gsv.CompileSchema(gsv.Record(gsv.String().Max(5), gsv.Int()).KeyPattern(`^x-`), &gsv.CompileSchemaOpts{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(compiled)).To(MatchJSON(`{
+				"type": "object",
+				"patternProperties": {"^x-": {"type": "integer"}},
+				"additionalProperties": false,
+				"propertyNames": {"type": "string", "maxLength": 5}
+			}`))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+package gsv_e2e_test
+
+import (
+	"strings"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Refine", func() {
+	It("adds custom validation to strings", func() {
+		schema := gsv.String().Refine(func(s string) bool {
+			return strings.ToLower(s) == s
+		}, gsv.ValidationOptions{Message: "must be lowercase"})
+
+		Expect(schema.Set("abc").Validate().HasErrors()).To(BeFalse())
+
+		result := schema.Set("ABC").Validate()
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.CustomError))
+		Expect(result.Errors[0].Message).To(Equal("must be lowercase"))
+		Expect(result.Errors[0].Actual).To(Equal("ABC"))
+	})
+
+	It("adds custom validation to numbers and booleans", func() {
+		even := gsv.Int().Refine(func(n int) bool { return n%2 == 0 })
+		Expect(even.Set(3).Validate().Errors[0].Message).To(Equal("invalid value"))
+
+		accepted := gsv.Bool().Refine(func(b bool) bool { return b })
+		Expect(accepted.Set(false).Validate().Errors[0].Type).To(Equal(gsv.CustomError))
+		Expect(accepted.Set(true).Validate().HasErrors()).To(BeFalse())
+	})
+
+	It("keeps refinements when cloned", func() {
+		schema := gsv.Record(gsv.String(), gsv.Int()).Refine(func(v map[string]interface{}) bool {
+			return len(v) > 1
+		}).Set(map[string]interface{}{"a": 1}).Clone()
+
+		result := schema.Validate()
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.CustomError))
+	})
+
+	It("only refines composite schemas once they're valid", func() {
+		called := false
+		schema := gsv.Object(map[string]gsv.Schema{
+			"name": gsv.String().Min(3),
+		}).Refine(func(map[string]interface{}) bool {
+			called = true
+			return false
+		})
+
+		result := schema.Set(map[string]interface{}{"name": "ab"}).Validate()
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+		Expect(called).To(BeFalse())
+
+		result = schema.Set(map[string]interface{}{"name": "abc"}).Validate()
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.CustomError))
+		Expect(called).To(BeTrue())
+	})
+
+	Context("with SuperRefine", func() {
+		type TestSignupSchema struct {
+			Account *gsv.ObjectSchema `json:"account"`
+		}
+
+		newSchema := func() *TestSignupSchema {
+			return &TestSignupSchema{
+				Account: gsv.Object(map[string]gsv.Schema{
+					"password":   gsv.String(),
+					"confirm":    gsv.String(),
+					"start_date": gsv.String().Date(),
+					"end_date":   gsv.String().Date(),
+				}).SuperRefine(func(ctx *gsv.RefinementContext, value map[string]interface{}) {
+					if value["password"] != value["confirm"] {
+						ctx.AddIssue(&gsv.ValidationError{
+							Path:    []interface{}{"confirm"},
+							Message: "passwords don't match",
+						})
+					}
+					if value["end_date"].(string) <= value["start_date"].(string) {
+						ctx.AddIssue(&gsv.ValidationError{
+							Type:     "date_order",
+							Path:     []interface{}{"end_date"},
+							Message:  "end_date must be after start_date",
+							Expected: value["start_date"],
+							Actual:   value["end_date"],
+						})
+					}
+				}),
+			}
+		}
+
+		It("reports errors at their paths", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{"account": {
+				"password": "a",
+				"confirm": "b",
+				"start_date": "2024-02-01",
+				"end_date": "2024-01-01"
+			}}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(2))
+
+			Expect(result.Errors[0].Type).To(Equal(gsv.CustomError))
+			Expect(result.Errors[0].Field).To(Equal("/account/confirm"))
+			Expect(result.Errors[0].Message).To(Equal("passwords don't match"))
+
+			Expect(result.Errors[1].Type).To(Equal(gsv.ValidationErrorType("date_order")))
+			Expect(result.Errors[1].Field).To(Equal("/account/end_date"))
+			Expect(result.Errors[1].Expected).To(Equal("2024-02-01"))
+		})
+
+		It("passes valid objects", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{"account": {
+				"password": "a",
+				"confirm": "a",
+				"start_date": "2024-01-01",
+				"end_date": "2024-02-01"
+			}}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,177 @@
+package gsv_e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	type TestAddArgs struct {
+		A *gsv.NumberSchema[int] `json:"a"`
+		B *gsv.NumberSchema[int] `json:"b"`
+	}
+
+	type TestEchoArgs struct {
+		Text *gsv.StringSchema `json:"text"`
+	}
+
+	var registry *gsv.Registry
+
+	BeforeEach(func() {
+		registry = gsv.NewRegistry()
+
+		gsv.Register(registry, gsv.NewTool("add", "Add two numbers", &TestAddArgs{
+			A: gsv.Int(),
+			B: gsv.Int().Max(100),
+		}), func(ctx context.Context, args *TestAddArgs) (any, error) {
+			a, _ := args.A.Value()
+			b, _ := args.B.Value()
+			return map[string]int{"sum": a + b}, nil
+		})
+
+		gsv.Register(registry, gsv.NewTool("echo", "Echo the text", &TestEchoArgs{
+			Text: gsv.String().Min(1),
+		}), func(ctx context.Context, args *TestEchoArgs) (any, error) {
+			text, _ := args.Text.Value()
+			if text == "fail" {
+				return nil, errors.New("echo is broken")
+			}
+			return text, nil
+		})
+	})
+
+	It("dispatches calls by name", func() {
+		result, err := registry.Dispatch(context.Background(), "add", []byte(`{"a": 1, "b": 2}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+		Expect(result.Tool).To(Equal("add"))
+		Expect(result.Content).To(MatchJSON(`{"sum": 3}`))
+
+		result, err = registry.Dispatch(context.Background(), "echo", []byte(`{"text": "hi"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Content).To(Equal("hi"))
+	})
+
+	It("returns validation errors the model can read", func() {
+		result, err := registry.Dispatch(context.Background(), "add", []byte(`{"a": 1, "b": 200}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Error.Fields).To(HaveLen(1))
+		Expect(result.Error.Fields[0].Field).To(Equal("/b"))
+		Expect(result.Error.Fields[0].Type).To(Equal(gsv.MaxNumberError))
+
+		var content map[string]interface{}
+		Expect(json.Unmarshal([]byte(result.Content), &content)).To(Succeed())
+		Expect(content).To(HaveKeyWithValue("error", "invalid arguments for tool add"))
+		Expect(content["fields"]).To(HaveLen(1))
+	})
+
+	It("returns an error result for arguments that can't be decoded", func() {
+		result, err := registry.Dispatch(context.Background(), "add", []byte(`{"a": "one"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Error.Message).To(HavePrefix("invalid arguments for tool add: "))
+	})
+
+	It("returns an error result for unknown tools", func() {
+		result, err := registry.Dispatch(context.Background(), "multiply", []byte(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Error.Message).To(Equal(`unknown tool "multiply", available tools: add, echo`))
+	})
+
+	It("returns the errors of handlers", func() {
+		_, err := registry.Dispatch(context.Background(), "echo", []byte(`{"text": "fail"}`))
+		Expect(err).To(MatchError("tool echo failed: echo is broken"))
+	})
+
+	It("doesn't share arguments between calls", func() {
+		var wg sync.WaitGroup
+		results := make([]*gsv.ToolResult, 20)
+
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+
+				result, err := registry.Dispatch(context.Background(), "add", []byte(fmt.Sprintf(`{"a": %d, "b": 1}`, i)))
+				Expect(err).ToNot(HaveOccurred())
+				results[i] = result
+			}(i)
+		}
+		wg.Wait()
+
+		for i, result := range results {
+			Expect(result.Content).To(MatchJSON(fmt.Sprintf(`{"sum": %d}`, i+1)))
+		}
+
+		// Missing arguments aren't filled in by earlier calls
+		result, err := registry.Dispatch(context.Background(), "add", []byte(`{"a": 1}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+	})
+
+	It("dispatches provider tool calls and renders their results", func() {
+		result, err := registry.DispatchAnthropic(context.Background(), &gsv.AnthropicToolUse{
+			Type:  "tool_use",
+			ID:    "toolu_01",
+			Name:  "echo",
+			Input: json.RawMessage(`{"text": ""}`),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		block := result.Anthropic()
+		Expect(block.Type).To(Equal("tool_result"))
+		Expect(block.ToolUseID).To(Equal("toolu_01"))
+		Expect(block.IsError).To(BeTrue())
+		Expect(block.Content).To(MatchJSON(`{
+			"error": "invalid arguments for tool echo",
+			"fields": [{"field": "/text", "type": "min_string_length", "message": "must be at least 1 characters long"}]
+		}`))
+
+		result, err = registry.DispatchOpenAI(context.Background(), &gsv.OpenAIToolCall{
+			ID:       "call_01",
+			Type:     "function",
+			Function: gsv.OpenAIFunctionCall{Name: "add", Arguments: `{"a": 2, "b": 2}`},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := json.Marshal(result.OpenAI())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{"role": "tool", "tool_call_id": "call_01", "content": "{\"sum\":4}"}`))
+	})
+
+	It("exports every tool definition in order", func() {
+		anthropic, err := registry.AnthropicTools()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(anthropic).To(HaveLen(2))
+		Expect(anthropic[0].Name).To(Equal("add"))
+		Expect(anthropic[1].Name).To(Equal("echo"))
+
+		openAI, err := registry.OpenAITools()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(openAI).To(HaveLen(2))
+		Expect(openAI[1].Function.Name).To(Equal("echo"))
+		Expect(string(openAI[1].Function.Parameters)).To(MatchJSON(`{
+			"type": "object",
+			"properties": {"text": {"type": "string", "minLength": 1}},
+			"required": ["text"]
+		}`))
+	})
+
+	It("panics on duplicate tools", func() {
+		Expect(func() {
+			gsv.Register(registry, gsv.NewTool("add", "", &TestAddArgs{}), func(ctx context.Context, args *TestAddArgs) (any, error) {
+				return nil, nil
+			})
+		}).To(Panic())
+	})
+})
@@ -0,0 +1,122 @@
+package gsv_e2e_test
+
+import (
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExtractJSON", func() {
+	// kinds returns the kinds of the repairs in order
+	kinds := func(repairs []gsv.Repair) []gsv.RepairKind {
+		out := []gsv.RepairKind{}
+		for _, r := range repairs {
+			out = append(out, r.Kind)
+		}
+		return out
+	}
+
+	It("returns valid JSON as is", func() {
+		data, repairs, err := gsv.ExtractJSON([]byte(`  {"a": [1, "b"]}` + "\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"a": [1, "b"]}`))
+		Expect(repairs).To(BeEmpty())
+	})
+
+	It("extracts the JSON from a code fence", func() {
+		text := "Here is the result:\n```json\n{\"a\": 1}\n```\nLet me know {if} you need more."
+
+		data, repairs, err := gsv.ExtractJSON([]byte(text))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{"a": 1}`))
+		Expect(kinds(repairs)).To(Equal([]gsv.RepairKind{gsv.RepairCodeFence}))
+		Expect(repairs[0].Offset).To(Equal(20))
+	})
+
+	It("removes the text around the JSON", func() {
+		data, repairs, err := gsv.ExtractJSON([]byte(`Sure! [1, 2, 3] Hope this helps.`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`[1, 2, 3]`))
+		Expect(kinds(repairs)).To(Equal([]gsv.RepairKind{gsv.RepairSurroundingText, gsv.RepairSurroundingText}))
+		Expect(repairs[1].Offset).To(Equal(15))
+	})
+
+	It("fixes trailing commas, single quotes and unquoted keys", func() {
+		data, repairs, err := gsv.ExtractJSON([]byte(`{name: 'O\'Brien', "say": 'he said "hi"', tags: ['a', 'b',],}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{"name": "O'Brien", "say": "he said \"hi\"", "tags": ["a", "b"]}`))
+		Expect(kinds(repairs)).To(Equal([]gsv.RepairKind{
+			gsv.RepairUnquotedKey,
+			gsv.RepairSingleQuotes,
+			gsv.RepairSingleQuotes,
+			gsv.RepairUnquotedKey,
+			gsv.RepairSingleQuotes,
+			gsv.RepairSingleQuotes,
+			gsv.RepairTrailingComma,
+			gsv.RepairTrailingComma,
+		}))
+		Expect(repairs[0].Message).To(Equal("quoted the key name"))
+	})
+
+	It("keeps commas and quotes inside strings", func() {
+		data, repairs, err := gsv.ExtractJSON([]byte(`{"a": "x, ]", 'b': "it's",}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{"a": "x, ]", "b": "it's"}`))
+		Expect(repairs).To(HaveLen(2))
+	})
+
+	It("closes truncated JSON", func() {
+		for text, expected := range map[string]string{
+			`{"a": [1, 2`:            `{"a": [1, 2]}`,
+			`{"a": "unfinished`:      `{"a": "unfinished"}`,
+			`{"a": 1, "b": {"c": 2,`: `{"a": 1, "b": {"c": 2}}`,
+			`{"a": 1, "b":`:          `{"a": 1, "b": null}`,
+			`{"a": 1, "bee`:          `{"a": 1, "bee": null}`,
+			"```json\n{\"a\": [1":    `{"a": [1]}`,
+		} {
+			data, repairs, err := gsv.ExtractJSON([]byte(text))
+			Expect(err).ToNot(HaveOccurred(), text)
+			Expect(string(data)).To(MatchJSON(expected), text)
+			Expect(kinds(repairs)).To(ContainElement(gsv.RepairTruncated), text)
+		}
+	})
+
+	It("fails when there's no JSON", func() {
+		_, _, err := gsv.ExtractJSON([]byte(`I can't help with that.`))
+		Expect(err).To(MatchError("no JSON object or array found"))
+
+		_, _, err = gsv.ExtractJSON([]byte(`{"a": nope}`))
+		Expect(err).To(MatchError(ContainSubstring("could not repair json")))
+	})
+
+	Context("with the Repair parse option", func() {
+		type TestReplySchema struct {
+			Answer *gsv.StringSchema      `json:"answer"`
+			Score  *gsv.NumberSchema[int] `json:"score"`
+		}
+
+		It("parses repaired model output and reports the repairs", func() {
+			schema := &TestReplySchema{Answer: gsv.String(), Score: gsv.Int().Max(10)}
+
+			result, err := gsv.Parse([]byte("```json\n{answer: 'yes', score: 11,}\n```"), schema,
+				gsv.ParseOptions{Repair: true})
+			Expect(err).ToNot(HaveOccurred())
+
+			answer, _ := schema.Answer.Value()
+			Expect(answer).To(Equal("yes"))
+
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MaxNumberError))
+
+			Expect(result.Warnings).To(HaveLen(5))
+			Expect(result.Warnings[0].Type).To(Equal(gsv.RepairWarning))
+			Expect(result.Warnings[0].Actual).To(Equal(gsv.RepairCodeFence))
+			Expect(result.Warnings[4].Actual).To(Equal(gsv.RepairTrailingComma))
+		})
+
+		It("isn't applied by default", func() {
+			_, err := gsv.Parse([]byte("{answer: 'yes'}"), &TestReplySchema{Answer: gsv.String(), Score: gsv.Int()})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,132 @@
+package gsv_e2e_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseWithRetry", func() {
+	type TestAnswerSchema struct {
+		Answer     *gsv.StringSchema          `json:"answer"`
+		Confidence *gsv.NumberSchema[float64] `json:"confidence"`
+	}
+
+	newSchema := func() *TestAnswerSchema {
+		return &TestAnswerSchema{
+			Answer:     gsv.String().Min(1),
+			Confidence: gsv.Float64().Min(0).Max(1),
+		}
+	}
+
+	// fakeGenerator returns the outputs in order and records the feedback it
+	// was called with
+	fakeGenerator := func(outputs ...string) (gsv.GenerateFunc, *[]string) {
+		feedback := []string{}
+		return func(ctx context.Context, f string) ([]byte, error) {
+			feedback = append(feedback, f)
+			return []byte(outputs[len(feedback)-1]), nil
+		}, &feedback
+	}
+
+	It("returns at the first valid output", func() {
+		generate, feedback := fakeGenerator(
+			`{"answer": "", "confidence": 2}`,
+			`{"answer": "42", "confidence": 0.9}`,
+			`{"answer": "unused", "confidence": 0.1}`,
+		)
+		schema := newSchema()
+
+		attempts, err := gsv.ParseWithRetry(context.Background(), generate, schema, 3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(HaveLen(2))
+		Expect(attempts[0].Valid()).To(BeFalse())
+		Expect(attempts[1].Valid()).To(BeTrue())
+
+		answer, _ := schema.Answer.Value()
+		Expect(answer).To(Equal("42"))
+
+		Expect(*feedback).To(HaveLen(2))
+		Expect((*feedback)[0]).To(BeEmpty())
+		Expect((*feedback)[1]).To(Equal("Your previous answer had these errors:\n" +
+			"- /answer: must be at least 1 characters long\n" +
+			"- /confidence: must not exceed: 1\n" +
+			"Fix them and answer again with the whole JSON object."))
+		Expect(attempts[1].Feedback).To(Equal((*feedback)[1]))
+	})
+
+	It("asks for JSON when the output can't be decoded", func() {
+		generate, feedback := fakeGenerator(
+			`Sure! {"answer": `,
+			`{"answer": "42", "confidence": 1}`,
+		)
+
+		attempts, err := gsv.ParseWithRetry(context.Background(), generate, newSchema(), 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts[0].Err).To(HaveOccurred())
+		Expect(attempts[0].Result).To(BeNil())
+		Expect((*feedback)[1]).To(HavePrefix("Your previous answer couldn't be parsed: "))
+	})
+
+	It("gives up after the max attempts", func() {
+		generate, _ := fakeGenerator(`{"answer": ""}`, `{"answer": "", "confidence": 0.5}`)
+		schema := newSchema()
+
+		attempts, err := gsv.ParseWithRetry(context.Background(), generate, schema, 2)
+		Expect(errors.Is(err, gsv.ErrMaxAttempts)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("must be at least 1 characters long"))
+		Expect(attempts).To(HaveLen(2))
+
+		// The schema is only updated by a valid output
+		_, ok := schema.Answer.Value()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("retries with PanicOnError and only panics once the attempts run out", func() {
+		opts := gsv.ParseOptions{ErrorMode: gsv.PanicOnError}
+
+		generate, _ := fakeGenerator(`{"answer": ""}`, `{"answer": "42", "confidence": 0.5}`)
+		attempts, err := gsv.ParseWithRetry(context.Background(), generate, newSchema(), 2, opts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(HaveLen(2))
+
+		generate, _ = fakeGenerator(`{"answer": ""}`, `{"answer": ""}`)
+		Expect(func() {
+			_, _ = gsv.ParseWithRetry(context.Background(), generate, newSchema(), 2, opts)
+		}).To(PanicWith(MatchError(ContainSubstring("must be at least 1 characters long"))))
+	})
+
+	It("doesn't carry values over between attempts", func() {
+		generate, feedback := fakeGenerator(`{"answer": "42", "confidence": 7}`, `{"confidence": 0.5}`)
+
+		attempts, err := gsv.ParseWithRetry(context.Background(), generate, newSchema(), 2)
+		Expect(err).To(HaveOccurred())
+		Expect(attempts[1].Result.Errors[0].Field).To(Equal("/answer"))
+		Expect((*feedback)[1]).To(ContainSubstring("/confidence"))
+	})
+
+	It("returns the errors of the generator", func() {
+		generate := func(ctx context.Context, feedback string) ([]byte, error) {
+			return nil, errors.New("rate limited")
+		}
+
+		attempts, err := gsv.ParseWithRetry(context.Background(), generate, newSchema(), 3)
+		Expect(err).To(MatchError("could not generate attempt 1: rate limited"))
+		Expect(attempts).To(BeEmpty())
+	})
+
+	It("stops when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		generate := func(ctx context.Context, feedback string) ([]byte, error) {
+			cancel()
+			return []byte(`{}`), nil
+		}
+
+		attempts, err := gsv.ParseWithRetry(ctx, generate, newSchema(), 3)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(attempts).To(HaveLen(1))
+	})
+})
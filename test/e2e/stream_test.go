@@ -0,0 +1,172 @@
+package gsv_e2e_test
+
+import (
+	"strings"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StreamParser", func() {
+	type TestArticleSchema struct {
+		Title  *gsv.StringSchema      `json:"title"`
+		Body   *gsv.StringSchema      `json:"body"`
+		Rating *gsv.NumberSchema[int] `json:"rating"`
+		Tags   *gsv.ArraySchema       `json:"tags"`
+		Author *gsv.ObjectSchema      `json:"author"`
+	}
+
+	newSchema := func() *TestArticleSchema {
+		return &TestArticleSchema{
+			Title:  gsv.String().Max(10),
+			Body:   gsv.String(),
+			Rating: gsv.Int().Max(5),
+			Tags:   gsv.Array(gsv.String()),
+			Author: gsv.Object(map[string]gsv.Schema{
+				"name":  gsv.String(),
+				"email": gsv.String().Email(),
+			}),
+		}
+	}
+
+	// write feeds the chunks one by one
+	write := func(p *gsv.StreamParser[TestArticleSchema], chunks ...string) {
+		for _, chunk := range chunks {
+			_, err := p.Write([]byte(chunk))
+			Expect(err).ToNot(HaveOccurred(), chunk)
+		}
+	}
+
+	It("shows fields as they complete", func() {
+		schema := newSchema()
+		p := gsv.NewStreamParser(schema)
+
+		write(p, `{"title": "Hel`)
+		title, ok := schema.Title.Value()
+		Expect(ok).To(BeTrue())
+		Expect(title).To(Equal("Hel"))
+		Expect(p.State("/title")).To(Equal(gsv.FieldInProgress))
+		Expect(p.State("/body")).To(Equal(gsv.FieldAbsent))
+
+		write(p, `lo", "body": "Once upon`)
+		title, _ = schema.Title.Value()
+		Expect(title).To(Equal("Hello"))
+		Expect(p.State("/title")).To(Equal(gsv.FieldComplete))
+		Expect(p.State("/body")).To(Equal(gsv.FieldInProgress))
+
+		body, _ := schema.Body.Value()
+		Expect(body).To(Equal("Once upon"))
+
+		write(p, ` a time", "rating": 4`)
+		rating, _ := schema.Rating.Value()
+		Expect(rating).To(Equal(4))
+		Expect(p.State("/rating")).To(Equal(gsv.FieldInProgress))
+
+		write(p, `, "tags": ["a", "b`)
+		Expect(p.State("/rating")).To(Equal(gsv.FieldComplete))
+		Expect(p.State("/tags")).To(Equal(gsv.FieldInProgress))
+		Expect(p.State("/tags/0")).To(Equal(gsv.FieldComplete))
+		tags, _ := schema.Tags.Value()
+		Expect(tags).To(Equal([]interface{}{"a", "b"}))
+
+		write(p, `"], "author": {"name": "Ada", "em`)
+		Expect(p.State("/author")).To(Equal(gsv.FieldInProgress))
+		Expect(p.State("/author/name")).To(Equal(gsv.FieldComplete))
+		Expect(p.State("/author/email")).To(Equal(gsv.FieldAbsent))
+		author, _ := schema.Author.Value()
+		Expect(author).To(Equal(map[string]interface{}{"name": "Ada"}))
+
+		write(p, `ail": "ada@example.com"}}`)
+		Expect(p.State("/author")).To(Equal(gsv.FieldComplete))
+
+		result, err := p.Close()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.HasErrors()).To(BeFalse())
+	})
+
+	It("only validates complete fields while streaming", func() {
+		p := gsv.NewStreamParser(newSchema())
+
+		write(p, `{"title": "A title that is too long`)
+		Expect(p.Result().HasErrors()).To(BeFalse())
+
+		write(p, `", "rating": 9`)
+		Expect(p.Result().Errors).To(HaveLen(1))
+		Expect(p.Result().Errors[0].Field).To(Equal("/title"))
+
+		write(p, `9, "author": {"name": "Ada", "email": "nope"`)
+		Expect(p.Result().Errors).To(HaveLen(3))
+		Expect(p.Result().Errors[1].Field).To(Equal("/rating"))
+		Expect(p.Result().Errors[2].Field).To(Equal("/author/email"))
+	})
+
+	It("validates the whole document when closed", func() {
+		p := gsv.NewStreamParser(newSchema())
+
+		write(p, `{"title": "Hi", "body": "text"}`)
+		Expect(p.Result().HasErrors()).To(BeFalse())
+
+		result, err := p.Close()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(3))
+	})
+
+	It("handles chunks split anywhere", func() {
+		doc := `{"title": "café ☕", "body": "a \"quoted\" line\n", "rating": -3, "tags": [], ` +
+			`"author": {"name": "Ada", "email": "ada@example.com"}}`
+
+		for size := 1; size <= 7; size++ {
+			schema := newSchema()
+			p := gsv.NewStreamParser(schema)
+
+			for i := 0; i < len(doc); i += size {
+				_, err := p.Write([]byte(doc[i:min(i+size, len(doc))]))
+				Expect(err).ToNot(HaveOccurred())
+
+				if body, ok := schema.Body.Value(); ok {
+					Expect(`a "quoted" line` + "\n").To(HavePrefix(body))
+				}
+			}
+
+			result, err := p.Close()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+
+			title, _ := schema.Title.Value()
+			Expect(title).To(Equal("café ☕"))
+		}
+	})
+
+	It("can be used as an io.Writer", func() {
+		p := gsv.NewStreamParser(newSchema())
+
+		n, err := strings.NewReader(`{"title": "Hi"`).WriteTo(p)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(int64(14)))
+		Expect(p.State("/title")).To(Equal(gsv.FieldComplete))
+	})
+
+	It("fails on malformed JSON", func() {
+		p := gsv.NewStreamParser(newSchema())
+
+		_, err := p.Write([]byte(`{"title" "Hi"`))
+		Expect(err).To(MatchError(ContainSubstring("invalid character")))
+	})
+
+	It("fails on misplaced commas", func() {
+		for _, data := range []string{
+			`{"title": "Hi",, "tags": [`,
+			`{"tags": ["a",, "b"`,
+			`{"tags": ["a" "b"`,
+			`{"tags": ["a",]`,
+			`{, "title": "Hi"`,
+		} {
+			_, err := gsv.NewStreamParser(newSchema()).Write([]byte(data))
+			Expect(err).To(MatchError(ContainSubstring("invalid character")), data)
+		}
+
+		_, err := gsv.NewStreamParser(newSchema()).Write([]byte(`{"title": "Hi", "tags": ["a",`))
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
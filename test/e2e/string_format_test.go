@@ -0,0 +1,61 @@
+package gsv_e2e_test
+
+import (
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StringSchema formats", func() {
+	DescribeTable("validates the string",
+		func(schema *gsv.StringSchema, valid, invalid string, errType gsv.ValidationErrorType) {
+			Expect(schema.Clone().(*gsv.StringSchema).Set(valid).Validate().HasErrors()).To(BeFalse())
+
+			result := schema.Set(invalid).Validate()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(errType))
+			Expect(result.Errors[0].Actual).To(Equal(invalid))
+		},
+		Entry("Regex", gsv.String().Regex(`^[a-z]+\d$`), "abc1", "abc", gsv.PatternStringError),
+		Entry("StartsWith", gsv.String().StartsWith("sk-"), "sk-123", "pk-123", gsv.StartsWithStringError),
+		Entry("EndsWith", gsv.String().EndsWith(".json"), "a.json", "a.yaml", gsv.EndsWithStringError),
+		Entry("Includes", gsv.String().Includes("@"), "a@b", "ab", gsv.IncludesStringError),
+		Entry("Email", gsv.String().Email(), "jane@example.com", "jane@", gsv.EmailStringError),
+		Entry("URL", gsv.String().URL(), "https://example.com/a?b=c", "example.com", gsv.URLStringError),
+		Entry("UUID", gsv.String().UUID(), "123e4567-e89b-12d3-a456-426614174000", "123e4567", gsv.UUIDStringError),
+		Entry("IPv4", gsv.String().IPv4(), "192.168.0.1", "256.1.1.1", gsv.IPv4StringError),
+		Entry("IPv6", gsv.String().IPv6(), "2001:db8::1", "192.168.0.1", gsv.IPv6StringError),
+		Entry("Hostname", gsv.String().Hostname(), "api.example.com", "-api.example.com", gsv.HostnameStringError),
+		Entry("DateTime", gsv.String().DateTime(), "2024-01-02T15:04:05.123+01:00", "2024-01-02 15:04:05", gsv.DateTimeStringError),
+		Entry("Date", gsv.String().Date(), "2024-02-29", "2023-02-29", gsv.DateStringError),
+		Entry("Duration", gsv.String().Duration(), "P1DT12H30M", "P1DT", gsv.DurationStringError),
+	)
+
+	It("uses the message from the options", func() {
+		result := gsv.String().Email(gsv.ValidationOptions{Message: "need an email"}).Set("x").Validate()
+		Expect(result.Errors[0].Message).To(Equal("need an email"))
+	})
+
+	It("compiles to pattern and format", func() {
+		compiled, err := gsv.CompileSchema(gsv.String().Regex(`^\d+$`).Email(), &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"type": "string",
+			"pattern": "^\\d+$",
+			"format": "email"
+		}`))
+	})
+
+	It("compiles additional patterns and formats to allOf", func() {
+		compiled, err := gsv.CompileSchema(gsv.String().StartsWith("a.b").EndsWith("z").Hostname().IPv4(), &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"type": "string",
+			"pattern": "^a\\.b",
+			"format": "hostname",
+			"allOf": [{"pattern": "z$"}, {"format": "ipv4"}]
+		}`))
+	})
+})
@@ -193,4 +193,49 @@ var _ = Describe("StringSchema", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Describe("Length modes", func() {
+		It("counts runes by default", func() {
+			schema := gsv.String().Min(8).Max(8).Set("日本語の名前です")
+
+			Expect(schema.Validate().HasErrors()).To(BeFalse())
+		})
+
+		It("counts bytes with Bytes", func() {
+			result := gsv.String().Max(20).Bytes().Set("日本語の名前です").Validate()
+
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MaxStringLengthError))
+			Expect(result.Errors[0].Message).To(Equal("must be at most 20 bytes long"))
+			Expect(result.Errors[0].Actual).To(Equal(24))
+		})
+
+		It("counts grapheme clusters with Graphemes", func() {
+			schema := gsv.String().Max(3).Graphemes()
+
+			Expect(schema.Set("👍🏽🇯🇵é").Validate().HasErrors()).To(BeFalse())
+			Expect(schema.Set("👨‍👩‍👧x").Validate().HasErrors()).To(BeFalse())
+
+			result := schema.Set("abcd").Validate()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Actual).To(Equal(4))
+		})
+
+		It("keeps the length mode when cloned", func() {
+			schema := gsv.String().Max(2).Bytes().Clone().(*gsv.StringSchema)
+
+			Expect(schema.Set("日").Validate().HasErrors()).To(BeTrue())
+		})
+
+		DescribeTable("compiles the lengths JSON schema can enforce",
+			func(schema *gsv.StringSchema, expected string) {
+				compiled, err := gsv.CompileSchema(schema, &gsv.CompileSchemaOpts{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(compiled)).To(MatchJSON(expected))
+			},
+			Entry("runes", gsv.String().Min(2).Max(10), `{"type": "string", "minLength": 2, "maxLength": 10}`),
+			Entry("bytes", gsv.String().Min(9).Max(10).Bytes(), `{"type": "string", "minLength": 3, "maxLength": 10}`),
+			Entry("graphemes", gsv.String().Min(2).Max(10).Graphemes(), `{"type": "string", "minLength": 2}`),
+		)
+	})
 })
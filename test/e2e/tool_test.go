@@ -0,0 +1,156 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tool", func() {
+	type TestWeatherArgs struct {
+		City *gsv.StringSchema       `json:"city"`
+		Unit *gsv.EnumSchema[string] `json:"unit"`
+	}
+
+	newTool := func() *gsv.Tool[TestWeatherArgs] {
+		return gsv.NewTool("get_weather", "Get the weather of a city", &TestWeatherArgs{
+			City: gsv.String().Min(1),
+			Unit: gsv.Enum("c", "f").Optional(),
+		})
+	}
+
+	Context("rendering definitions", func() {
+		It("renders the Anthropic tool", func() {
+			def, err := newTool().Anthropic()
+			Expect(err).ToNot(HaveOccurred())
+
+			data, err := json.Marshal(def)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(MatchJSON(`{
+				"name": "get_weather",
+				"description": "Get the weather of a city",
+				"input_schema": {
+					"type": "object",
+					"properties": {
+						"city": {"type": "string", "minLength": 1},
+						"unit": {"type": "string", "enum": ["c", "f"]}
+					},
+					"required": ["city"]
+				}
+			}`))
+		})
+
+		It("renders the OpenAI tool", func() {
+			def, err := newTool().OpenAI()
+			Expect(err).ToNot(HaveOccurred())
+
+			data, err := json.Marshal(def)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(MatchJSON(`{
+				"type": "function",
+				"function": {
+					"name": "get_weather",
+					"description": "Get the weather of a city",
+					"parameters": {
+						"type": "object",
+						"properties": {
+							"city": {"type": "string", "minLength": 1},
+							"unit": {"type": "string", "enum": ["c", "f"]}
+						},
+						"required": ["city"]
+					}
+				}
+			}`))
+		})
+
+		It("renders the OpenAI tool for strict mode", func() {
+			tool := newTool()
+			tool.Strict = true
+
+			def, err := tool.OpenAI()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(def.Function.Strict).To(BeTrue())
+			Expect(string(def.Function.Parameters)).To(MatchJSON(`{
+				"type": "object",
+				"properties": {
+					"city": {"type": "string"},
+					"unit": {"type": ["string", "null"], "enum": ["c", "f", null]}
+				},
+				"required": ["city", "unit"],
+				"additionalProperties": false
+			}`))
+		})
+
+		It("rejects names the providers don't accept", func() {
+			tool := newTool()
+			tool.Name = "get weather"
+
+			_, err := tool.Anthropic()
+			Expect(err).To(MatchError(ContainSubstring(`invalid tool name "get weather"`)))
+		})
+	})
+
+	Context("parsing tool calls", func() {
+		It("parses an Anthropic tool_use block", func() {
+			tool := newTool()
+
+			result, err := tool.ParseAnthropic([]byte(`{
+				"type": "tool_use",
+				"id": "toolu_01",
+				"name": "get_weather",
+				"input": {"city": "Paris", "unit": "c"}
+			}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+
+			city, _ := tool.Schema.City.Value()
+			Expect(city).To(Equal("Paris"))
+			unit, _ := tool.Schema.Unit.Value()
+			Expect(unit).To(Equal("c"))
+		})
+
+		It("parses the arguments string of an OpenAI tool call", func() {
+			tool := newTool()
+
+			result, err := tool.ParseOpenAI([]byte(`{
+				"id": "call_01",
+				"type": "function",
+				"function": {"name": "get_weather", "arguments": "{\"city\": \"\"}"}
+			}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+			Expect(result.Errors[0].Field).To(Equal("/city"))
+		})
+
+		It("passes the parse options on", func() {
+			tool := gsv.NewTool("add", "", &struct {
+				A *gsv.NumberSchema[int] `json:"a"`
+			}{A: gsv.Int()})
+
+			result, err := tool.ParseOpenAIToolCall(&gsv.OpenAIToolCall{
+				Function: gsv.OpenAIFunctionCall{Name: "add", Arguments: `{"a": "1"}`},
+			}, gsv.ParseOptions{Coerce: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Warnings).To(HaveLen(1))
+		})
+
+		It("rejects calls of other tools", func() {
+			_, err := newTool().ParseAnthropic([]byte(`{"type": "tool_use", "name": "get_time", "input": {}}`))
+			Expect(err).To(MatchError(ContainSubstring(`tool call for "get_time"`)))
+
+			_, err = newTool().ParseAnthropic([]byte(`{"type": "text", "text": "hi"}`))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fails on arguments that aren't JSON", func() {
+			_, err := newTool().ParseOpenAI([]byte(`{
+				"type": "function",
+				"function": {"name": "get_weather", "arguments": "{\"city\": "}
+			}`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
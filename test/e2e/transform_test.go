@@ -0,0 +1,166 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transform", func() {
+	trim := func(s string) (string, error) { return strings.TrimSpace(s), nil }
+	lower := func(s string) (string, error) { return strings.ToLower(s), nil }
+
+	Context("with a same type transform", func() {
+		It("validates the normalized value", func() {
+			schema := gsv.String().Transform(trim).Transform(lower).Email().Max(16)
+
+			Expect(json.Unmarshal([]byte(`"  Jane@Example.com  "`), schema)).To(Succeed())
+
+			val, ok := schema.Value()
+			Expect(ok).To(BeTrue())
+			Expect(val).To(Equal("jane@example.com"))
+		})
+
+		It("reports transform errors", func() {
+			schema := gsv.Int().Transform(func(n int) (int, error) {
+				if n < 0 {
+					return 0, fmt.Errorf("cannot be rounded")
+				}
+				return n / 10 * 10, nil
+			})
+
+			Expect(json.Unmarshal([]byte(`15`), schema)).To(Succeed())
+			val, _ := schema.Value()
+			Expect(val).To(Equal(10))
+
+			err := json.Unmarshal([]byte(`-5`), schema)
+			Expect(err).To(HaveOccurred())
+
+			result := schema.Validate()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.TransformError))
+			Expect(result.Errors[0].Message).To(Equal("cannot be rounded"))
+		})
+
+		It("keeps transform errors of array elements", func() {
+			schema := gsv.Array(gsv.String().Transform(func(s string) (string, error) {
+				if s == "" {
+					return "", fmt.Errorf("empty")
+				}
+				return s, nil
+			}))
+
+			result, err := gsv.Parse([]byte(`{"tags": ["a", ""]}`), &struct {
+				Tags *gsv.ArraySchema `json:"tags"`
+			}{Tags: schema})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.TransformError))
+			Expect(result.Errors[0].Field).To(Equal("/tags/1"))
+		})
+	})
+
+	Context("with Preprocess", func() {
+		It("rewrites the JSON value before decoding", func() {
+			schema := gsv.Bool().Preprocess(func(v interface{}) interface{} {
+				if s, ok := v.(string); ok {
+					return s == "yes"
+				}
+				return v
+			})
+
+			Expect(json.Unmarshal([]byte(`"yes"`), schema)).To(Succeed())
+			val, ok := schema.Value()
+			Expect(ok).To(BeTrue())
+			Expect(val).To(BeTrue())
+		})
+
+		It("runs before the transforms", func() {
+			schema := gsv.String().Preprocess(func(v interface{}) interface{} {
+				if n, ok := v.(json.Number); ok {
+					return n.String()
+				}
+				return v
+			}).Transform(func(s string) (string, error) { return "#" + s, nil })
+
+			Expect(json.Unmarshal([]byte(`42`), schema)).To(Succeed())
+			val, _ := schema.Value()
+			Expect(val).To(Equal("#42"))
+		})
+
+		It("keeps the precision of large integers", func() {
+			schema := gsv.Int64().Preprocess(func(v interface{}) interface{} { return v })
+
+			Expect(json.Unmarshal([]byte(`9007199254740993`), schema)).To(Succeed())
+			val, _ := schema.Value()
+			Expect(val).To(Equal(int64(9007199254740993)))
+		})
+	})
+
+	Context("with a typed transform", func() {
+		type TestTaskSchema struct {
+			Due *gsv.TransformSchema[string, time.Time] `json:"due"`
+		}
+
+		newSchema := func() *TestTaskSchema {
+			return &TestTaskSchema{
+				Due: gsv.Transform(gsv.String().Date(), func(s string) (time.Time, error) {
+					return time.Parse(time.DateOnly, s)
+				}),
+			}
+		}
+
+		It("returns the output type from Value", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{"due": "2024-01-02"}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.HasErrors()).To(BeFalse())
+
+			due, ok := schema.Due.Value()
+			Expect(ok).To(BeTrue())
+			Expect(due).To(Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("reports the errors of the inner schema", func() {
+			schema := newSchema()
+
+			result, err := gsv.Parse([]byte(`{"due": "tomorrow"}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Type).To(Equal(gsv.DateStringError))
+			Expect(result.Errors[0].Field).To(Equal("/due"))
+
+			_, ok := schema.Due.Value()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("refines the output", func() {
+			schema := newSchema()
+			schema.Due.Refine(func(t time.Time) bool {
+				return t.Year() >= 2025
+			}, gsv.ValidationOptions{Message: "must be in 2025 or later"})
+
+			result, err := gsv.Parse([]byte(`{"due": "2024-01-02"}`), schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Message).To(Equal("must be in 2025 or later"))
+		})
+
+		It("compiles the inner schema", func() {
+			compiled, err := gsv.CompileSchema(newSchema(), &gsv.CompileSchemaOpts{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(compiled)).To(MatchJSON(`{
+				"type": "object",
+				"properties": {"due": {"type": "string", "format": "date"}},
+				"required": ["due"]
+			}`))
+		})
+	})
+})
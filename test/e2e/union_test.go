@@ -0,0 +1,201 @@
+package gsv_e2e_test
+
+import (
+	"encoding/json"
+
+	"github.com/agent-api/gsv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UnionSchema", func() {
+	It("matches the first variant that validates", func() {
+		schema := gsv.Union(gsv.String().Min(3), gsv.Int())
+
+		Expect(json.Unmarshal([]byte(`42`), schema)).To(Succeed())
+
+		index, variant, ok := schema.Matched()
+		Expect(ok).To(BeTrue())
+		Expect(index).To(Equal(1))
+		Expect(variant).To(BeAssignableToTypeOf(&gsv.IntSchema{}))
+
+		val, ok := schema.Value()
+		Expect(ok).To(BeTrue())
+		Expect(val).To(Equal(42))
+	})
+
+	It("collects the errors of every variant", func() {
+		schema := gsv.Union(gsv.String().Min(3), gsv.String().Max(1))
+
+		err := json.Unmarshal([]byte(`"ab"`), schema)
+		Expect(err).To(HaveOccurred())
+
+		result := schema.Validate()
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.InvalidUnionError))
+		Expect(result.Errors[0].Causes).To(HaveLen(2))
+		Expect(result.Errors[0].Causes[0].Type).To(Equal(gsv.MinStringLengthError))
+		Expect(result.Errors[0].Causes[1].Type).To(Equal(gsv.MaxStringLengthError))
+
+		_, _, ok := schema.Matched()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("validates the candidates once per parse with the parse options", func() {
+		calls := 0
+		type TestUnionSchema struct {
+			Value *gsv.UnionSchema `json:"value"`
+		}
+		schema := &TestUnionSchema{
+			Value: gsv.Union(
+				gsv.String().Refine(func(s string) bool {
+					calls++
+					return s != "reserved"
+				}),
+				gsv.String().Min(10).Max(1),
+			),
+		}
+
+		result, err := gsv.Parse([]byte(`{"value": "reserved"}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal(1))
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Causes).To(HaveLen(3))
+
+		result, err = gsv.Parse([]byte(`{"value": "reserved"}`), schema, gsv.ParseOptions{StopOnFirst: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal(2))
+		Expect(result.Errors[0].Causes).To(HaveLen(2))
+	})
+
+	It("returns an error when no variant can decode the value", func() {
+		schema := gsv.Union(gsv.String(), gsv.Int())
+
+		err := json.Unmarshal([]byte(`true`), schema)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid union value"))
+	})
+
+	It("compiles to anyOf", func() {
+		compiled, err := gsv.CompileSchema(gsv.Union(gsv.String(), gsv.Int()), &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"anyOf": [{"type": "string"}, {"type": "integer"}]
+		}`))
+	})
+})
+
+var _ = Describe("DiscriminatedUnionSchema", func() {
+	type TestAgentSchema struct {
+		Action *gsv.DiscriminatedUnionSchema `json:"action"`
+	}
+
+	newSchema := func() *TestAgentSchema {
+		return &TestAgentSchema{
+			Action: gsv.DiscriminatedUnion("type", map[string]*gsv.ObjectSchema{
+				"search": gsv.Object(map[string]gsv.Schema{
+					"query": gsv.String().Min(1),
+				}),
+				"click": gsv.Object(map[string]gsv.Schema{
+					"selector": gsv.String(),
+				}).Strict(),
+			}),
+		}
+	}
+
+	It("dispatches on the tag", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"action": {"type": "click", "selector": "#submit"}}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.HasErrors()).To(BeFalse())
+
+		tag, variant, ok := schema.Action.Matched()
+		Expect(ok).To(BeTrue())
+		Expect(tag).To(Equal("click"))
+		selector, ok := variant.Field("selector").(*gsv.StringSchema).Value()
+		Expect(ok).To(BeTrue())
+		Expect(selector).To(Equal("#submit"))
+
+		val, ok := schema.Action.Value()
+		Expect(ok).To(BeTrue())
+		Expect(val).To(Equal(map[string]interface{}{"type": "click", "selector": "#submit"}))
+	})
+
+	It("reports the errors of the matched variant", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"action": {"type": "search", "query": ""}}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.MinStringLengthError))
+		Expect(result.Errors[0].Field).To(Equal("/action/query"))
+	})
+
+	It("reports unknown tags with the known ones", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"action": {"type": "scroll"}}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.InvalidDiscriminatorValueError))
+		Expect(result.Errors[0].Field).To(Equal("/action/type"))
+		Expect(result.Errors[0].Message).To(Equal(`must be one of "click", "search"`))
+		Expect(result.Errors[0].Expected).To(Equal([]string{"click", "search"}))
+	})
+
+	It("reports a missing tag", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"action": {"query": "x"}}`), schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].Type).To(Equal(gsv.RequiredDiscriminatorError))
+	})
+
+	It("reports unknown keys of the matched variant in Strict mode", func() {
+		schema := newSchema()
+
+		result, err := gsv.Parse([]byte(`{"action": {"type": "search", "qeury": "x"}}`), schema, gsv.ParseOptions{
+			Strict: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Errors[0].Type).To(Equal(gsv.UnknownObjectKeyError))
+		Expect(result.Errors[0].Field).To(Equal("/action/qeury"))
+		Expect(result.Errors[0].Expected).To(Equal("query"))
+	})
+
+	It("compiles to oneOf with the tag as a const", func() {
+		compiled, err := gsv.CompileSchema(newSchema(), &gsv.CompileSchemaOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(compiled)).To(MatchJSON(`{
+			"type": "object",
+			"properties": {
+				"action": {
+					"oneOf": [
+						{
+							"type": "object",
+							"properties": {
+								"selector": {"type": "string"},
+								"type": {"type": "string", "const": "click"}
+							},
+							"required": ["selector", "type"],
+							"additionalProperties": false
+						},
+						{
+							"type": "object",
+							"properties": {
+								"query": {"type": "string", "minLength": 1},
+								"type": {"type": "string", "const": "search"}
+							},
+							"required": ["query", "type"]
+						}
+					]
+				}
+			},
+			"required": ["action"]
+		}`))
+	})
+})
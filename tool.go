@@ -0,0 +1,203 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// toolNamePattern matches the tool names both Anthropic and OpenAI accept
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Tool bundles a gsv schema struct with the name and description a model
+// needs to call it. It renders itself into the tool definitions of the
+// Anthropic and OpenAI APIs and parses their tool calls into its schema.
+type Tool[T any] struct {
+	Name        string
+	Description string
+
+	// Schema is the gsv schema struct holding the tool's arguments. Every
+	// parsed tool call is decoded into it.
+	Schema *T
+
+	// Strict compiles the OpenAI parameters for strict mode and sets the
+	// function's strict flag. See TargetOpenAIStrict.
+	Strict bool
+}
+
+// NewTool creates a tool for the schema struct. It panics if schema is nil.
+func NewTool[T any](name, description string, schema *T) *Tool[T] {
+	if schema == nil {
+		panic("tool schema can't be nil")
+	}
+
+	return &Tool[T]{
+		Name:        name,
+		Description: description,
+		Schema:      schema,
+	}
+}
+
+// AnthropicTool is the definition of a tool in the Anthropic Messages API
+type AnthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// OpenAITool is the definition of a function tool in the OpenAI Chat
+// Completions API
+type OpenAITool struct {
+	Type     string         `json:"type"`
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction is the function of an OpenAITool
+type OpenAIFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+	Strict      bool            `json:"strict,omitempty"`
+}
+
+// AnthropicToolUse is a tool_use content block of an Anthropic message
+type AnthropicToolUse struct {
+	Type  string          `json:"type"`
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// OpenAIToolCall is an entry of the tool_calls of an OpenAI message
+type OpenAIToolCall struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"`
+	Function OpenAIFunctionCall `json:"function"`
+}
+
+// OpenAIFunctionCall is the function of an OpenAIToolCall. The arguments are
+// a string holding the JSON object the model generated.
+type OpenAIFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Anthropic renders the tool definition for the Anthropic Messages API
+func (t *Tool[T]) Anthropic() (*AnthropicTool, error) {
+	inputSchema, err := t.compile(TargetJSONSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnthropicTool{
+		Name:        t.Name,
+		Description: t.Description,
+		InputSchema: inputSchema,
+	}, nil
+}
+
+// OpenAI renders the tool definition for the OpenAI Chat Completions API
+func (t *Tool[T]) OpenAI() (*OpenAITool, error) {
+	target := TargetJSONSchema
+	if t.Strict {
+		target = TargetOpenAIStrict
+	}
+
+	parameters, err := t.compile(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenAITool{
+		Type: "function",
+		Function: OpenAIFunction{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  parameters,
+			Strict:      t.Strict,
+		},
+	}, nil
+}
+
+// compile compiles the tool's schema after checking its name. The
+// description belongs to the tool so it isn't repeated in the schema.
+func (t *Tool[T]) compile(target CompileTarget) (json.RawMessage, error) {
+	if !toolNamePattern.MatchString(t.Name) {
+		return nil, fmt.Errorf("invalid tool name %q: must match %s", t.Name, toolNamePattern)
+	}
+
+	compiled, err := CompileSchema(t.Schema, &CompileSchemaOpts{}, target)
+	if err != nil {
+		return nil, fmt.Errorf("could not compile tool %s: %w", t.Name, err)
+	}
+
+	return compiled, nil
+}
+
+// ParseAnthropic parses the input of a tool_use content block into the
+// tool's schema. The block must be a call of this tool.
+func (t *Tool[T]) ParseAnthropic(block []byte, opts ...ParseOptions) (*ValidationResult, error) {
+	var toolUse AnthropicToolUse
+	if err := json.Unmarshal(block, &toolUse); err != nil {
+		return nil, fmt.Errorf("could not unmarshal tool_use block: %w", err)
+	}
+
+	return t.ParseAnthropicToolUse(&toolUse, opts...)
+}
+
+// ParseAnthropicToolUse is ParseAnthropic for a block that was already
+// decoded, e.g. as part of the whole message
+func (t *Tool[T]) ParseAnthropicToolUse(toolUse *AnthropicToolUse, opts ...ParseOptions) (*ValidationResult, error) {
+	if toolUse.Type != "" && toolUse.Type != "tool_use" {
+		return nil, fmt.Errorf("expected a tool_use block, got %q", toolUse.Type)
+	}
+
+	if err := t.checkName(toolUse.Name); err != nil {
+		return nil, err
+	}
+
+	return t.parse(toolUse.Input, opts)
+}
+
+// ParseOpenAI parses the arguments of an entry of tool_calls into the tool's
+// schema. The entry must be a call of this tool.
+func (t *Tool[T]) ParseOpenAI(call []byte, opts ...ParseOptions) (*ValidationResult, error) {
+	var toolCall OpenAIToolCall
+	if err := json.Unmarshal(call, &toolCall); err != nil {
+		return nil, fmt.Errorf("could not unmarshal tool call: %w", err)
+	}
+
+	return t.ParseOpenAIToolCall(&toolCall, opts...)
+}
+
+// ParseOpenAIToolCall is ParseOpenAI for a tool call that was already
+// decoded, e.g. as part of the whole message
+func (t *Tool[T]) ParseOpenAIToolCall(toolCall *OpenAIToolCall, opts ...ParseOptions) (*ValidationResult, error) {
+	if toolCall.Type != "" && toolCall.Type != "function" {
+		return nil, fmt.Errorf("expected a function tool call, got %q", toolCall.Type)
+	}
+
+	if err := t.checkName(toolCall.Function.Name); err != nil {
+		return nil, err
+	}
+
+	return t.parse([]byte(toolCall.Function.Arguments), opts)
+}
+
+// checkName returns an error if the call is for another tool
+func (t *Tool[T]) checkName(name string) error {
+	if name != t.Name {
+		return fmt.Errorf("tool call for %q can't be parsed by tool %q", name, t.Name)
+	}
+	return nil
+}
+
+// parse parses the arguments into the tool's schema. Tools without arguments
+// may be called with no input at all, which is parsed as an empty object.
+func (t *Tool[T]) parse(arguments []byte, opts []ParseOptions) (*ValidationResult, error) {
+	if len(arguments) == 0 {
+		arguments = []byte("{}")
+	}
+
+	return Parse(arguments, t.Schema, opts...)
+}
@@ -0,0 +1,292 @@
+package gsv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+const (
+	TransformError ValidationErrorType = "transform"
+)
+
+// preprocessFunc rewrites the decoded JSON value before a schema decodes it.
+// The value is what encoding/json decodes into an interface{} with UseNumber:
+// nil, bool, json.Number, string, []interface{} or map[string]interface{}.
+// Numbers are kept as json.Number so large integers don't lose precision.
+type preprocessFunc func(interface{}) interface{}
+
+// preprocess runs the preprocess functions over the JSON data and returns the
+// JSON of the result. The data is returned as is without preprocess functions
+// and for missing values, which have nothing to rewrite.
+func preprocess(data []byte, preprocessors []preprocessFunc) ([]byte, error) {
+	if len(data) == 0 || len(preprocessors) == 0 {
+		return data, nil
+	}
+
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	for _, fn := range preprocessors {
+		v = fn(v)
+	}
+
+	preprocessed, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid preprocessed value: %w", err)
+	}
+
+	return preprocessed, nil
+}
+
+// transformValue runs the same type transforms over v, stopping at the first
+// one that fails
+func transformValue[T any](v T, transforms []func(T) (T, error)) (T, error) {
+	for _, transform := range transforms {
+		var err error
+		if v, err = transform(v); err != nil {
+			return v, err
+		}
+	}
+
+	return v, nil
+}
+
+// newTransformError builds the error reported for a failed transform
+func newTransformError(err error, actual interface{}) *ValidationError {
+	return &ValidationError{
+		Type:    TransformError,
+		Message: err.Error(),
+		Actual:  actual,
+	}
+}
+
+// TransformSchema implements the Schema interface for a schema whose value is
+// transformed into another type, like a date string into a time.Time. The
+// inner schema validates the JSON input and the transform produces the output.
+type TransformSchema[In, Out any] struct {
+	// inner decodes and validates the input
+	inner Schema
+
+	transform func(In) (Out, error)
+
+	// output is the transformed value, nil if the inner schema has no value or
+	// the transform failed
+	output *Out
+
+	// transformErr is the error of the last transform
+	transformErr error
+
+	// refinements are the custom validations of the output
+	refinements []refinement[Out]
+
+	// the result of the last validation
+	result *ValidationResult
+}
+
+// Transform creates a new schema that transforms the value of the inner
+// schema. The transform runs whenever the inner schema gets a value, so
+// Value returns the output right after decoding. Errors of the transform are
+// reported as TransformError when validating, after the inner schema's errors.
+//
+//	due := gsv.Transform(gsv.String().Date(), func(s string) (time.Time, error) {
+//		return time.Parse(time.DateOnly, s)
+//	})
+func Transform[In, Out any](inner Schema, transform func(In) (Out, error)) *TransformSchema[In, Out] {
+	if inner == nil {
+		panic("inner schema cannot be nil")
+	}
+	if transform == nil {
+		panic("transform cannot be nil")
+	}
+
+	return &TransformSchema[In, Out]{
+		inner:     inner,
+		transform: transform,
+		result:    &ValidationResult{},
+	}
+}
+
+// Inner returns the schema of the input
+func (t *TransformSchema[In, Out]) Inner() Schema {
+	return t.inner
+}
+
+// Refine adds a custom validation of the output. It only runs once the input
+// is valid and transformed.
+func (t *TransformSchema[In, Out]) Refine(check func(Out) bool, opts ...ValidationOptions) *TransformSchema[In, Out] {
+	t.refinements = append(t.refinements, newRefinement(check, opts))
+	return t
+}
+
+// IsOptional implements Schema.IsOptional
+func (t *TransformSchema[In, Out]) IsOptional() bool {
+	return t.inner.IsOptional()
+}
+
+// IsNullable implements Schema.IsNullable
+func (t *TransformSchema[In, Out]) IsNullable() bool {
+	return t.inner.IsNullable()
+}
+
+// State implements Schema.State
+func (t *TransformSchema[In, Out]) State() ValueState {
+	return t.inner.State()
+}
+
+// Set provides a way to set the input value, which is transformed right away
+func (t *TransformSchema[In, Out]) Set(v In) *TransformSchema[In, Out] {
+	t.result = &ValidationResult{}
+
+	if err := t.setValue(v); err != nil {
+		t.result.AddError(&ValidationError{
+			Type:    TransformError,
+			Message: err.Error(),
+			Actual:  v,
+		})
+	}
+
+	return t
+}
+
+func (t *TransformSchema[In, Out]) setValue(val interface{}) error {
+	if err := t.inner.setValue(val); err != nil {
+		return err
+	}
+
+	t.apply()
+
+	return nil
+}
+
+// apply transforms the value of the inner schema
+func (t *TransformSchema[In, Out]) apply() {
+	t.output = nil
+	t.transformErr = nil
+
+	val, ok := t.inner.getValue()
+	if !ok || val == nil {
+		return
+	}
+
+	in, ok := val.(In)
+	if !ok {
+		t.transformErr = fmt.Errorf("expected %T value, got %T", *new(In), val)
+		return
+	}
+
+	out, err := t.transform(in)
+	if err != nil {
+		t.transformErr = err
+		return
+	}
+
+	t.output = &out
+}
+
+// getValue returns the output, or the input if it couldn't be transformed
+func (t *TransformSchema[In, Out]) getValue() (interface{}, bool) {
+	if t.output != nil {
+		return *t.output, true
+	}
+	return t.inner.getValue()
+}
+
+// Value returns the transformed value. This method returns the zero value
+// and false if the inner schema has no value or the transform failed.
+func (t *TransformSchema[In, Out]) Value() (Out, bool) {
+	if t.output == nil {
+		var zero Out
+		return zero, false
+	}
+	return *t.output, true
+}
+
+// Validate performs the validation
+func (t *TransformSchema[In, Out]) Validate() *ValidationResult {
+	return t.validate(&ParseOptions{})
+}
+
+func (t *TransformSchema[In, Out]) validate(opts *ParseOptions) *ValidationResult {
+	t.result = &ValidationResult{}
+
+	inner := t.inner.validate(opts)
+	t.result.Errors = append(t.result.Errors, inner.Errors...)
+	t.result.Warnings = append(t.result.Warnings, inner.Warnings...)
+	if t.result.HasErrors() {
+		return t.result
+	}
+
+	if t.transformErr != nil {
+		val, _ := t.inner.getValue()
+		t.result.AddError(newTransformError(t.transformErr, val))
+		return t.result
+	}
+
+	if t.output != nil {
+		runRefinements(t.refinements, *t.output, t.result, opts)
+	}
+
+	return t.result
+}
+
+// MarshalJSON implements json.Marshaler. The input is marshaled since the
+// output may not have a JSON representation.
+func (t *TransformSchema[In, Out]) MarshalJSON() ([]byte, error) {
+	return t.inner.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *TransformSchema[In, Out]) UnmarshalJSON(data []byte) error {
+	if err := t.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	return t.Validate().Error()
+}
+
+func (t *TransformSchema[In, Out]) decode(data []byte, opts *ParseOptions) error {
+	if err := t.inner.decode(data, opts); err != nil {
+		return err
+	}
+
+	t.apply()
+
+	return nil
+}
+
+// CompileJSONSchema implements Schema.CompileJSONSchema. The JSON schema
+// describes the input so it's the one of the inner schema.
+func (t *TransformSchema[In, Out]) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error {
+	if t == nil {
+		return fmt.Errorf("found nil schema interface with JSON tag: %s", jsonTag)
+	}
+
+	return t.inner.CompileJSONSchema(schema, jsonTag)
+}
+
+// Clone implements Schema.Clone by creating a deep copy of the TransformSchema
+func (t *TransformSchema[In, Out]) Clone() Schema {
+	clone := &TransformSchema[In, Out]{
+		inner:        t.inner.Clone(),
+		transform:    t.transform,
+		transformErr: t.transformErr,
+		refinements:  make([]refinement[Out], len(t.refinements)),
+		result:       &ValidationResult{},
+	}
+
+	copy(clone.refinements, t.refinements)
+
+	if t.output != nil {
+		out := *t.output
+		clone.output = &out
+	}
+
+	return clone
+}
@@ -0,0 +1,901 @@
+package gsv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/agent-api/gsv/pkg/jsonschema"
+)
+
+const (
+	RequiredUnionError             ValidationErrorType = "required_union"
+	InvalidUnionError              ValidationErrorType = "invalid_union"
+	RequiredDiscriminatorError     ValidationErrorType = "required_discriminator"
+	InvalidDiscriminatorValueError ValidationErrorType = "invalid_discriminator_value"
+)
+
+// UnionSchema implements the Schema interface for values that must match at
+// least one of several schemas. Variants are tried in order and the first one
+// that validates is the one that matched.
+type UnionSchema struct {
+	// variants are the schemas the value is checked against
+	variants []Schema
+
+	// candidates hold a clone of every variant that accepted the value, at the
+	// index of the variant, or nil for the variants that rejected it
+	candidates []Schema
+
+	// matched is the index of the variant that matched or -1
+	matched int
+
+	// isMatched denotes the candidates have been validated since the last
+	// decode or set
+	isMatched bool
+
+	// raw holds the value as it was given, for when no variant matched
+	raw interface{}
+
+	// isSet denotes that the union has a value
+	isSet bool
+
+	// refinements are the custom validations of the matched value
+	refinements []refinement[interface{}]
+
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[interface{}]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
+
+	description *string
+
+	// isOptional denotes if the value in the schema is optional
+	isOptional bool
+
+	// the result of the last validation
+	result *ValidationResult
+}
+
+// Union creates a new schema that accepts values matching any of the variants
+func Union(variants ...Schema) *UnionSchema {
+	if len(variants) == 0 {
+		panic("union requires at least one variant")
+	}
+
+	for i, variant := range variants {
+		if variant == nil {
+			panic(fmt.Sprintf("union variant %d cannot be nil", i))
+		}
+	}
+
+	return &UnionSchema{
+		variants: variants,
+		matched:  -1,
+		result:   &ValidationResult{},
+	}
+}
+
+// Refine adds a custom validation. It only runs once a variant matched.
+func (u *UnionSchema) Refine(check func(interface{}) bool, opts ...ValidationOptions) *UnionSchema {
+	u.refinements = append(u.refinements, newRefinement(check, opts))
+	return u
+}
+
+// Description sets the description of the union
+func (u *UnionSchema) Description(val string) *UnionSchema {
+	u.description = &val
+	return u
+}
+
+// Nullable marks JSON null as a valid value of the union. Unlike Optional,
+// the key must still be present unless the union is optional as well.
+func (u *UnionSchema) Nullable() *UnionSchema {
+	u.isNullable = true
+	return u
+}
+
+// IsNullable implements Schema.IsNullable
+func (u *UnionSchema) IsNullable() bool {
+	return u.isNullable
+}
+
+// State implements Schema.State
+func (u *UnionSchema) State() ValueState {
+	_, ok := u.getValue()
+	return valueState(u.isNull, ok)
+}
+
+// Default marks the union field as optional and sets the value it takes when
+// it's missing. It panics if the union doesn't accept v.
+func (u *UnionSchema) Default(v interface{}) *UnionSchema {
+	if err := u.Clone().setValue(v); err != nil {
+		panic(fmt.Sprintf("invalid default: %v", err))
+	}
+
+	u.defaultValue = newDefault(v)
+	u.isOptional = true
+	return u
+}
+
+// DefaultFunc is Default with a value built by fn for every missing union
+func (u *UnionSchema) DefaultFunc(fn func() interface{}) *UnionSchema {
+	u.defaultValue = newDefaultFunc(fn)
+	u.isOptional = true
+	return u
+}
+
+// Optional marks the union field as optional
+func (u *UnionSchema) Optional() *UnionSchema {
+	u.isOptional = true
+	return u
+}
+
+// IsOptional implements Schema.IsOptional
+func (u *UnionSchema) IsOptional() bool {
+	return u.isOptional
+}
+
+// Variants returns the schemas of the union
+func (u *UnionSchema) Variants() []Schema {
+	return u.variants
+}
+
+// Matched returns the index of the variant that matched the value and the
+// variant's schema holding the value. It returns (-1, nil, false) if no
+// variant matched.
+func (u *UnionSchema) Matched() (int, Schema, bool) {
+	u.lazyMatch()
+	if u.matched == -1 {
+		return -1, nil, false
+	}
+	return u.matched, u.candidates[u.matched], true
+}
+
+// Set provides a way to set the union's value
+func (u *UnionSchema) Set(v interface{}) *UnionSchema {
+	u.result = &ValidationResult{}
+
+	if err := u.setValue(v); err != nil {
+		u.result.AddError(&ValidationError{
+			Type:    InvalidUnionError,
+			Message: err.Error(),
+			Actual:  v,
+		})
+	}
+
+	return u
+}
+
+func (u *UnionSchema) setValue(val interface{}) error {
+	if val == nil && u.isNullable {
+		u.reset()
+		u.isNull = true
+		return nil
+	}
+	u.isNull = false
+
+	u.reset()
+
+	var errs []error
+	for i, variant := range u.variants {
+		candidate := variant.Clone()
+		if err := candidate.setValue(val); err != nil {
+			errs = append(errs, fmt.Errorf("variant %d: %w", i, err))
+			continue
+		}
+		u.candidates[i] = candidate
+	}
+
+	if len(errs) == len(u.variants) {
+		return fmt.Errorf("value matches no variant: %w", errors.Join(errs...))
+	}
+
+	u.raw = val
+	u.isSet = true
+
+	return nil
+}
+
+func (u *UnionSchema) getValue() (interface{}, bool) {
+	if u.isNull {
+		return nil, true
+	}
+
+	if !u.isSet {
+		return nil, false
+	}
+	u.lazyMatch()
+	if u.matched != -1 {
+		return u.candidates[u.matched].getValue()
+	}
+	return u.raw, true
+}
+
+// Value returns the value of the matched variant, or the value as it was given
+// if no variant matched. This method returns (nil, false) if the union hasn't
+// been set or is null.
+func (u *UnionSchema) Value() (interface{}, bool) {
+	if u.isNull {
+		return nil, false
+	}
+	return u.getValue()
+}
+
+// reset clears the value and candidates of the last decode or set
+func (u *UnionSchema) reset() {
+	u.candidates = make([]Schema, len(u.variants))
+	u.matched = -1
+	u.isMatched = false
+	u.raw = nil
+	u.isSet = false
+}
+
+// match validates the candidates in order, stopping at the first one without
+// errors. It returns the warnings of the matched candidate and the errors of
+// the candidates that failed.
+func (u *UnionSchema) match(opts *ParseOptions) ([]*ValidationError, []*ValidationError) {
+	u.matched = -1
+	u.isMatched = true
+
+	var causes []*ValidationError
+	for i, candidate := range u.candidates {
+		if candidate == nil {
+			continue
+		}
+
+		result := candidate.validate(opts)
+		if !result.HasErrors() {
+			u.matched = i
+			return result.Warnings, nil
+		}
+
+		causes = append(causes, result.Errors...)
+	}
+
+	return nil, causes
+}
+
+// lazyMatch picks the matched candidate when the value is read before it has
+// been validated
+func (u *UnionSchema) lazyMatch() {
+	if u.isSet && !u.isMatched {
+		u.match(&ParseOptions{})
+	}
+}
+
+// Validate performs the validation
+func (u *UnionSchema) Validate() *ValidationResult {
+	return u.validate(&ParseOptions{})
+}
+
+func (u *UnionSchema) validate(opts *ParseOptions) *ValidationResult {
+	u.result = &ValidationResult{}
+
+	if u.isNull {
+		if !u.isNullable {
+			u.result.AddError(newNullError())
+		}
+		return u.result
+	}
+
+	if !u.isSet {
+		if !u.isOptional {
+			u.result.AddError(&ValidationError{
+				Type:    RequiredUnionError,
+				Message: "value has not been set",
+			})
+		}
+		return u.result
+	}
+
+	warnings, causes := u.match(opts)
+	if u.matched == -1 {
+		u.result.AddError(&ValidationError{
+			Type:    InvalidUnionError,
+			Message: fmt.Sprintf("value matches none of the %d variants", len(u.variants)),
+			Actual:  u.raw,
+			Causes:  causes,
+		})
+		return u.result
+	}
+
+	u.result.Warnings = warnings
+
+	value, _ := u.getValue()
+	runRefinements(u.refinements, value, u.result, opts)
+
+	return u.result
+}
+
+// MarshalJSON implements json.Marshaler
+func (u *UnionSchema) MarshalJSON() ([]byte, error) {
+	if u.isNull {
+		return json.Marshal(nil)
+	}
+
+	if !u.isSet {
+		if u.isOptional {
+			return json.Marshal(nil)
+		}
+		return nil, fmt.Errorf("required union has no value")
+	}
+	u.lazyMatch()
+	if u.matched != -1 {
+		return u.candidates[u.matched].MarshalJSON()
+	}
+	return json.Marshal(u.raw)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (u *UnionSchema) UnmarshalJSON(data []byte) error {
+	if err := u.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	return u.Validate().Error()
+}
+
+func (u *UnionSchema) decode(data []byte, opts *ParseOptions) error {
+	u.reset()
+
+	u.isNull = string(data) == "null"
+
+	if len(data) == 0 || string(data) == "null" {
+		applyDefault(u, u.defaultValue)
+		return nil
+	}
+
+	var errs []error
+	for i, variant := range u.variants {
+		candidate := variant.Clone()
+		if err := candidate.decode(data, opts); err != nil {
+			errs = append(errs, fmt.Errorf("variant %d: %w", i, err))
+			continue
+		}
+		u.candidates[i] = candidate
+	}
+
+	if len(errs) == len(u.variants) {
+		return fmt.Errorf("invalid union value: %w", errors.Join(errs...))
+	}
+
+	if err := json.Unmarshal(data, &u.raw); err != nil {
+		return fmt.Errorf("invalid union value: %w", err)
+	}
+
+	u.isSet = true
+
+	return nil
+}
+
+// CompileJSONSchema implements Schema.CompileJSONSchema. Variants may overlap
+// so the union compiles to "anyOf".
+func (u *UnionSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error {
+	if u == nil {
+		return fmt.Errorf("found nil schema interface with JSON tag: %s", jsonTag)
+	}
+
+	unionSchema := &jsonschema.JSONSchema{}
+
+	if u.description != nil {
+		unionSchema.Description = *u.description
+	}
+	unionSchema.Default = u.defaultValue.jsonSchemaDefault()
+
+	for i, variant := range u.variants {
+		variantSchema, err := compileStandalone(variant)
+		if err != nil {
+			return fmt.Errorf("failed to compile union variant %d: %w", i, err)
+		}
+		unionSchema.AnyOf = append(unionSchema.AnyOf, variantSchema)
+	}
+	if u.isNullable {
+		unionSchema.AnyOf = append(unionSchema.AnyOf, &jsonschema.JSONSchema{Type: jsonschema.Types(NullSchemaType)})
+	}
+
+	schema.Properties[jsonTag] = unionSchema
+	if !u.isOptional {
+		schema.Required = append(schema.Required, jsonTag)
+	}
+	return nil
+}
+
+// Clone implements Schema.Clone by creating a deep copy of the UnionSchema
+func (u *UnionSchema) Clone() Schema {
+	clone := &UnionSchema{
+		variants:    make([]Schema, len(u.variants)),
+		matched:     u.matched,
+		isMatched:   u.isMatched,
+		raw:         u.raw,
+		isSet:       u.isSet,
+		refinements: make([]refinement[interface{}], len(u.refinements)),
+		isOptional:  u.isOptional,
+		result:      &ValidationResult{},
+	}
+
+	copy(clone.refinements, u.refinements)
+
+	for i, variant := range u.variants {
+		clone.variants[i] = variant.Clone()
+	}
+
+	if u.candidates != nil {
+		clone.candidates = make([]Schema, len(u.candidates))
+		for i, candidate := range u.candidates {
+			if candidate != nil {
+				clone.candidates[i] = candidate.Clone()
+			}
+		}
+	}
+	if u.description != nil {
+		desc := *u.description
+		clone.description = &desc
+	}
+
+	clone.defaultValue = u.defaultValue
+	clone.isNullable = u.isNullable
+	clone.isNull = u.isNull
+
+	return clone
+}
+
+// DiscriminatedUnionSchema implements the Schema interface for objects that
+// are one of several shapes, told apart by the string value of a tag key, like
+// {"type": "search", "query": "..."} and {"type": "click", "selector": "..."}.
+type DiscriminatedUnionSchema struct {
+	// discriminator is the key of the tag
+	discriminator string
+
+	// variants map every tag value to the schema of its object
+	variants map[string]*ObjectSchema
+
+	// tags are the tag values in a stable order
+	tags []string
+
+	// tag is the tag value of the decoded object, nil if the tag is missing
+	tag *string
+
+	// matched holds the value in a clone of the variant of the tag, nil if
+	// the tag is missing or unknown
+	matched *ObjectSchema
+
+	// isSet denotes that the union has a value
+	isSet bool
+
+	// refinements are the custom validations of the matched object
+	refinements []refinement[map[string]interface{}]
+
+	// defaultValue is the value used when the value is missing
+	defaultValue *defaultValue[map[string]interface{}]
+
+	// isNullable denotes JSON null is a valid value and isNull that the value
+	// is null
+	isNullable bool
+	isNull     bool
+
+	description *string
+
+	// isOptional denotes if the value in the schema is optional
+	isOptional bool
+
+	// the result of the last validation
+	result *ValidationResult
+}
+
+// DiscriminatedUnion creates a new schema that dispatches objects to one of
+// the variants using the value of the discriminator key. Variants that don't
+// have the discriminator key in their shape get it as a Literal of their tag.
+func DiscriminatedUnion(discriminator string, variants map[string]*ObjectSchema) *DiscriminatedUnionSchema {
+	if len(variants) == 0 {
+		panic("discriminated union requires at least one variant")
+	}
+
+	d := &DiscriminatedUnionSchema{
+		discriminator: discriminator,
+		variants:      make(map[string]*ObjectSchema, len(variants)),
+		tags:          sortedKeys(variants),
+		result:        &ValidationResult{},
+	}
+
+	for tag, variant := range variants {
+		if variant == nil {
+			panic(fmt.Sprintf("discriminated union variant %q cannot be nil", tag))
+		}
+
+		if _, ok := variant.shape[discriminator]; !ok {
+			variant = variant.Clone().(*ObjectSchema)
+			variant.shape[discriminator] = Literal(tag)
+			variant.keys = append(variant.keys, discriminator)
+			sort.Strings(variant.keys)
+		}
+
+		d.variants[tag] = variant
+	}
+
+	return d
+}
+
+// Refine adds a custom validation. It only runs once the matched variant is
+// valid.
+func (d *DiscriminatedUnionSchema) Refine(check func(map[string]interface{}) bool, opts ...ValidationOptions) *DiscriminatedUnionSchema {
+	d.refinements = append(d.refinements, newRefinement(check, opts))
+	return d
+}
+
+// Description sets the description of the union
+func (d *DiscriminatedUnionSchema) Description(val string) *DiscriminatedUnionSchema {
+	d.description = &val
+	return d
+}
+
+// Nullable marks JSON null as a valid value of the union. Unlike Optional,
+// the key must still be present unless the union is optional as well.
+func (d *DiscriminatedUnionSchema) Nullable() *DiscriminatedUnionSchema {
+	d.isNullable = true
+	return d
+}
+
+// IsNullable implements Schema.IsNullable
+func (d *DiscriminatedUnionSchema) IsNullable() bool {
+	return d.isNullable
+}
+
+// State implements Schema.State
+func (d *DiscriminatedUnionSchema) State() ValueState {
+	_, ok := d.getValue()
+	return valueState(d.isNull, ok)
+}
+
+// Default marks the union field as optional and sets the value it takes when
+// it's missing. It panics if the union doesn't accept v.
+func (d *DiscriminatedUnionSchema) Default(v map[string]interface{}) *DiscriminatedUnionSchema {
+	if err := d.Clone().setValue(v); err != nil {
+		panic(fmt.Sprintf("invalid default: %v", err))
+	}
+
+	d.defaultValue = newDefault(v)
+	d.isOptional = true
+	return d
+}
+
+// DefaultFunc is Default with a value built by fn for every missing union
+func (d *DiscriminatedUnionSchema) DefaultFunc(fn func() map[string]interface{}) *DiscriminatedUnionSchema {
+	d.defaultValue = newDefaultFunc(fn)
+	d.isOptional = true
+	return d
+}
+
+// Optional marks the union field as optional
+func (d *DiscriminatedUnionSchema) Optional() *DiscriminatedUnionSchema {
+	d.isOptional = true
+	return d
+}
+
+// IsOptional implements Schema.IsOptional
+func (d *DiscriminatedUnionSchema) IsOptional() bool {
+	return d.isOptional
+}
+
+// Discriminator returns the key of the tag
+func (d *DiscriminatedUnionSchema) Discriminator() string {
+	return d.discriminator
+}
+
+// Variants returns the schema of every tag value
+func (d *DiscriminatedUnionSchema) Variants() map[string]*ObjectSchema {
+	return d.variants
+}
+
+// Matched returns the tag value and the variant's schema holding the value.
+// It returns ("", nil, false) if the tag is missing or unknown.
+func (d *DiscriminatedUnionSchema) Matched() (string, *ObjectSchema, bool) {
+	if d.matched == nil {
+		return "", nil, false
+	}
+	return *d.tag, d.matched, true
+}
+
+// Set provides a way to set the union's value
+func (d *DiscriminatedUnionSchema) Set(values map[string]interface{}) *DiscriminatedUnionSchema {
+	d.result = &ValidationResult{}
+
+	if err := d.setValue(values); err != nil {
+		d.result.AddError(&ValidationError{
+			Type:    InvalidObjectValueError,
+			Message: err.Error(),
+		})
+	}
+
+	return d
+}
+
+func (d *DiscriminatedUnionSchema) setValue(val interface{}) error {
+	if val == nil && d.isNullable {
+		d.reset()
+		d.isNull = true
+		return nil
+	}
+	d.isNull = false
+
+	values, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected object value, got %T", val)
+	}
+
+	d.reset()
+
+	if raw, ok := values[d.discriminator]; ok {
+		tag, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string value, got %T", d.discriminator, raw)
+		}
+		d.tag = &tag
+	}
+
+	if variant := d.variant(); variant != nil {
+		if err := variant.setValue(values); err != nil {
+			return err
+		}
+		d.matched = variant
+	}
+
+	d.isSet = true
+
+	return nil
+}
+
+func (d *DiscriminatedUnionSchema) getValue() (interface{}, bool) {
+	if d.isNull {
+		return nil, true
+	}
+
+	if !d.isSet {
+		return nil, false
+	}
+	if d.matched == nil {
+		return map[string]interface{}{}, true
+	}
+	return d.matched.getValue()
+}
+
+// Value returns the values of the matched variant keyed by their JSON key.
+// This method returns (nil, false) if the union hasn't been set.
+func (d *DiscriminatedUnionSchema) Value() (map[string]interface{}, bool) {
+	val, ok := d.getValue()
+	if !ok || val == nil {
+		return nil, false
+	}
+	objVal, ok := val.(map[string]interface{})
+	if !ok {
+		panic(fmt.Sprintf("DiscriminatedUnionSchema: invalid internal value type %T, expected map[string]interface{}", val))
+	}
+	return objVal, true
+}
+
+// reset clears the value of the last decode or set
+func (d *DiscriminatedUnionSchema) reset() {
+	d.tag = nil
+	d.matched = nil
+	d.isSet = false
+}
+
+// variant returns a fresh clone of the variant of the tag or nil if the tag
+// is missing or unknown
+func (d *DiscriminatedUnionSchema) variant() *ObjectSchema {
+	if d.tag == nil {
+		return nil
+	}
+
+	variant, ok := d.variants[*d.tag]
+	if !ok {
+		return nil
+	}
+
+	return variant.Clone().(*ObjectSchema)
+}
+
+// Validate performs the validation
+func (d *DiscriminatedUnionSchema) Validate() *ValidationResult {
+	return d.validate(&ParseOptions{})
+}
+
+func (d *DiscriminatedUnionSchema) validate(opts *ParseOptions) *ValidationResult {
+	d.result = &ValidationResult{}
+
+	if d.isNull {
+		if !d.isNullable {
+			d.result.AddError(newNullError())
+		}
+		return d.result
+	}
+
+	if !d.isSet {
+		if !d.isOptional {
+			d.result.AddError(&ValidationError{
+				Type:    RequiredUnionError,
+				Message: "value has not been set",
+			})
+		}
+		return d.result
+	}
+
+	path := []interface{}{d.discriminator}
+
+	if d.tag == nil {
+		d.result.AddError(&ValidationError{
+			Type:     RequiredDiscriminatorError,
+			Field:    jsonPointer(path),
+			Path:     path,
+			Message:  fmt.Sprintf("discriminator %q is required", d.discriminator),
+			Expected: d.tags,
+		})
+		return d.result
+	}
+
+	if d.matched == nil {
+		d.result.AddError(&ValidationError{
+			Type:     InvalidDiscriminatorValueError,
+			Field:    jsonPointer(path),
+			Path:     path,
+			Message:  fmt.Sprintf("must be one of %s", d.formatTags()),
+			Expected: d.tags,
+			Actual:   *d.tag,
+		})
+		return d.result
+	}
+
+	matched := d.matched.validate(opts)
+	d.result.Errors = matched.Errors
+	d.result.Warnings = matched.Warnings
+
+	value, _ := d.Value()
+	runRefinements(d.refinements, value, d.result, opts)
+
+	return d.result
+}
+
+// formatTags formats the tag values for error messages
+func (d *DiscriminatedUnionSchema) formatTags() string {
+	formatted := make([]string, len(d.tags))
+	for i, tag := range d.tags {
+		formatted[i] = formatEnumValue(tag)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// MarshalJSON implements json.Marshaler
+func (d *DiscriminatedUnionSchema) MarshalJSON() ([]byte, error) {
+	if d.isNull {
+		return json.Marshal(nil)
+	}
+
+	if !d.isSet {
+		if d.isOptional {
+			return json.Marshal(nil)
+		}
+		return nil, fmt.Errorf("required union has no value")
+	}
+	if d.matched == nil {
+		return nil, fmt.Errorf("union has no matched variant")
+	}
+	return d.matched.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *DiscriminatedUnionSchema) UnmarshalJSON(data []byte) error {
+	if err := d.decode(data, &ParseOptions{}); err != nil {
+		return err
+	}
+
+	return d.Validate().Error()
+}
+
+func (d *DiscriminatedUnionSchema) decode(data []byte, opts *ParseOptions) error {
+	d.reset()
+
+	d.isNull = string(data) == "null"
+
+	if len(data) == 0 || string(data) == "null" {
+		applyDefault(d, d.defaultValue)
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("invalid object value: %w", err)
+	}
+
+	if raw, ok := fields[d.discriminator]; ok {
+		var tag string
+		if err := json.Unmarshal(raw, &tag); err != nil {
+			return fmt.Errorf("%s: invalid discriminator value: %w", d.discriminator, err)
+		}
+		d.tag = &tag
+	}
+
+	if variant := d.variant(); variant != nil {
+		if err := variant.decode(data, opts); err != nil {
+			return err
+		}
+		d.matched = variant
+	}
+
+	d.isSet = true
+
+	return nil
+}
+
+// CompileJSONSchema implements Schema.CompileJSONSchema. Tags never overlap
+// so the union compiles to "oneOf".
+func (d *DiscriminatedUnionSchema) CompileJSONSchema(schema *jsonschema.JSONSchema, jsonTag string) error {
+	if d == nil {
+		return fmt.Errorf("found nil schema interface with JSON tag: %s", jsonTag)
+	}
+
+	unionSchema := &jsonschema.JSONSchema{}
+
+	if d.description != nil {
+		unionSchema.Description = *d.description
+	}
+	unionSchema.Default = d.defaultValue.jsonSchemaDefault()
+
+	for _, tag := range d.tags {
+		variantSchema, err := compileStandalone(d.variants[tag])
+		if err != nil {
+			return fmt.Errorf("failed to compile union variant %q: %w", tag, err)
+		}
+		unionSchema.OneOf = append(unionSchema.OneOf, variantSchema)
+	}
+	if d.isNullable {
+		unionSchema.OneOf = append(unionSchema.OneOf, &jsonschema.JSONSchema{Type: jsonschema.Types(NullSchemaType)})
+	}
+
+	schema.Properties[jsonTag] = unionSchema
+	if !d.isOptional {
+		schema.Required = append(schema.Required, jsonTag)
+	}
+	return nil
+}
+
+// Clone implements Schema.Clone by creating a deep copy of the
+// DiscriminatedUnionSchema
+func (d *DiscriminatedUnionSchema) Clone() Schema {
+	clone := &DiscriminatedUnionSchema{
+		discriminator: d.discriminator,
+		variants:      make(map[string]*ObjectSchema, len(d.variants)),
+		tags:          make([]string, len(d.tags)),
+		isSet:         d.isSet,
+		refinements:   make([]refinement[map[string]interface{}], len(d.refinements)),
+		isOptional:    d.isOptional,
+		result:        &ValidationResult{},
+	}
+
+	copy(clone.tags, d.tags)
+	copy(clone.refinements, d.refinements)
+	for tag, variant := range d.variants {
+		clone.variants[tag] = variant.Clone().(*ObjectSchema)
+	}
+
+	if d.tag != nil {
+		tag := *d.tag
+		clone.tag = &tag
+	}
+	if d.matched != nil {
+		clone.matched = d.matched.Clone().(*ObjectSchema)
+	}
+	if d.description != nil {
+		desc := *d.description
+		clone.description = &desc
+	}
+
+	clone.defaultValue = d.defaultValue
+	clone.isNullable = d.isNullable
+	clone.isNull = d.isNull
+
+	return clone
+}
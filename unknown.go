@@ -0,0 +1,223 @@
+package gsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// findUnknownFields walks the JSON data alongside the decoded schema v and
+// returns an error for every key that isn't part of the schema. The closest
+// known key is suggested for each of them.
+//
+// Object schemas in passthrough or catchall mode accept unknown keys by design
+// and strict object schemas already report them, so only the ones stripping
+// unknown keys are checked.
+func findUnknownFields(v reflect.Value, data []byte, path []interface{}) []*ValidationError {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		if v.CanInterface() {
+			if schema, ok := v.Interface().(Schema); ok {
+				return findUnknownSchemaFields(schema, data, path)
+			}
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	fields := jsonFields(v)
+	known := make([]string, 0, len(fields))
+	for _, field := range fields {
+		known = append(known, field.name)
+	}
+
+	var errs []*ValidationError
+	for _, key := range sortedKeys(raw) {
+		field, ok := matchField(fields, key)
+		if !ok {
+			errs = append(errs, newUnknownKeyError(key, known, path))
+			continue
+		}
+
+		errs = append(errs, findUnknownFields(field.value, raw[key], appendPath(path, key))...)
+	}
+
+	return errs
+}
+
+// findUnknownSchemaFields looks for unknown keys in the JSON data of a schema.
+// Only schemas that contain objects can hold unknown keys.
+func findUnknownSchemaFields(schema Schema, data []byte, path []interface{}) []*ValidationError {
+	switch s := schema.(type) {
+	case *ObjectSchema:
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil
+		}
+
+		var errs []*ValidationError
+		for _, key := range sortedKeys(raw) {
+			field, ok := s.shape[key]
+			if !ok {
+				if s.mode == stripUnknownKeys {
+					errs = append(errs, newUnknownKeyError(key, s.keys, path))
+				}
+				continue
+			}
+
+			errs = append(errs, findUnknownSchemaFields(field, raw[key], appendPath(path, key))...)
+		}
+
+		return errs
+
+	case *ArraySchema:
+		var raw []json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil
+		}
+
+		var errs []*ValidationError
+		for i, elem := range raw {
+			errs = append(errs, findUnknownSchemaFields(s.elementSchema, elem, appendPath(path, i))...)
+		}
+
+		return errs
+
+	case *RecordSchema:
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil
+		}
+
+		var errs []*ValidationError
+		for _, key := range sortedKeys(raw) {
+			errs = append(errs, findUnknownSchemaFields(s.valueSchema, raw[key], appendPath(path, key))...)
+		}
+
+		return errs
+
+	case interface{ Inner() Schema }:
+		// Transforms don't change the keys of the JSON input
+		return findUnknownSchemaFields(s.Inner(), data, path)
+
+	case *UnionSchema:
+		if _, matched, ok := s.Matched(); ok {
+			return findUnknownSchemaFields(matched, data, path)
+		}
+
+	case *DiscriminatedUnionSchema:
+		if _, matched, ok := s.Matched(); ok {
+			return findUnknownSchemaFields(matched, data, path)
+		}
+	}
+
+	return nil
+}
+
+// matchField finds the struct field for a JSON key the same way the decoder
+// does: an exact match first, then a case-insensitive one
+func matchField(fields []jsonField, key string) (jsonField, bool) {
+	for _, field := range fields {
+		if field.name == key {
+			return field, true
+		}
+	}
+
+	for _, field := range fields {
+		if strings.EqualFold(field.name, key) {
+			return field, true
+		}
+	}
+
+	return jsonField{}, false
+}
+
+// newUnknownKeyError builds the UnknownObjectKeyError for an unknown key,
+// suggesting the closest of the known keys if there is one that's close enough
+func newUnknownKeyError(key string, known []string, path []interface{}) *ValidationError {
+	fieldPath := appendPath(path, key)
+
+	err := &ValidationError{
+		Type:    UnknownObjectKeyError,
+		Field:   jsonPointer(fieldPath),
+		Path:    fieldPath,
+		Message: fmt.Sprintf("unknown key %q", key),
+		Actual:  key,
+	}
+
+	if suggestion, ok := closestKey(key, known); ok {
+		err.Message = fmt.Sprintf("unknown key %q, did you mean %q?", key, suggestion)
+		err.Expected = suggestion
+	}
+
+	return err
+}
+
+// closestKey returns the known key with the smallest edit distance to key. A
+// key is only suggested when at most a third of it needs to change, so typos
+// and swapped letters are caught without suggesting unrelated keys.
+func closestKey(key string, known []string) (string, bool) {
+	best := ""
+	bestDistance := -1
+
+	for _, candidate := range known {
+		distance := editDistance(strings.ToLower(key), strings.ToLower(candidate))
+		if bestDistance == -1 || distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	threshold := max(1, len([]rune(best))/3)
+	if bestDistance == -1 || bestDistance > threshold {
+		return "", false
+	}
+
+	return best, true
+}
+
+// editDistance returns the number of single rune insertions, deletions,
+// substitutions and transpositions of adjacent runes needed to turn a into b
+// (the optimal string alignment distance)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}